@@ -1,9 +1,16 @@
 module foxygo.at/s
 
-go 1.14
+go 1.22
 
 require (
 	github.com/alecthomas/kong v0.2.12
 	github.com/stretchr/testify v1.6.1
 	golang.org/x/sync v0.0.0-20200317015054-43a5402ce75a
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pkg/errors v0.8.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
@@ -0,0 +1,136 @@
+package httpe
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// principalKey is the context key for the principal of an authenticated
+// request.
+type principalKey struct{}
+
+// PrincipalFrom returns the principal of type P stored in ctx by one of
+// the authentication handlers, such as BasicAuth, and true, or the zero
+// value of P and false if there is none.
+func PrincipalFrom[P any](ctx context.Context) (P, bool) {
+	p, ok := ctx.Value(principalKey{}).(P)
+	return p, ok
+}
+
+// withPrincipal returns a shallow copy of r with the principal p stored
+// in its context.
+func withPrincipal[P any](r *http.Request, p P) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), principalKey{}, p))
+}
+
+// BasicAuth returns a RequestHandlerE that authenticates a request with
+// the user and password of HTTP Basic authentication. Use with Chain or
+// New/Must.
+//
+// The user and password are passed to verify, which returns the principal
+// they identify and true, or false if they are not valid. On success, the
+// principal is stored in the context of the request derived for the
+// handlers that follow in the Chain, and can be retrieved with
+// PrincipalFrom. Missing or invalid credentials result in ErrUnauthorized
+// with a Basic challenge for realm. An error returned by verify is
+// returned unchanged.
+func BasicAuth[P any](realm string, verify func(ctx context.Context, user, password string) (P, bool, error)) Guard {
+	challenge := "Basic realm=" + quote(realm) + `, charset="UTF-8"`
+	f := func(_ http.ResponseWriter, r *http.Request) (*http.Request, error) {
+		user, password, ok := r.BasicAuth()
+		if !ok {
+			return nil, fmt.Errorf("%w: missing credentials", Unauthorized(challenge))
+		}
+		return authenticate(r, challenge, func(ctx context.Context) (P, bool, error) {
+			return verify(ctx, user, password)
+		})
+	}
+	return Guard{Name: "BasicAuth(" + realm + ")", Handler: RequestHandlerFuncE(f)}
+}
+
+// BearerAuth returns a RequestHandlerE that authenticates a request with
+// the token of Bearer authentication as described in RFC 6750. Use with
+// Chain or New/Must.
+//
+// The token is passed to verify, which returns the principal it
+// identifies and true, or false if it is not valid. On success, the
+// principal is stored in the context of the request derived for the
+// handlers that follow in the Chain, and can be retrieved with
+// PrincipalFrom. A missing or invalid token results in ErrUnauthorized
+// with a Bearer challenge for realm. An error returned by verify is
+// returned unchanged.
+func BearerAuth[P any](realm string, verify func(ctx context.Context, token string) (P, bool, error)) Guard {
+	challenge := "Bearer realm=" + quote(realm)
+	f := func(_ http.ResponseWriter, r *http.Request) (*http.Request, error) {
+		scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return nil, fmt.Errorf("%w: missing credentials", Unauthorized(challenge))
+		}
+		return authenticate(r, challenge+`, error="invalid_token"`, func(ctx context.Context) (P, bool, error) {
+			return verify(ctx, token)
+		})
+	}
+	return Guard{Name: "BearerAuth(" + realm + ")", Handler: RequestHandlerFuncE(f)}
+}
+
+// APIKeyAuth returns a RequestHandlerE that authenticates a request with
+// an API key in the named request header, such as X-API-Key. Use with
+// Chain or New/Must.
+//
+// The key is passed to verify, which returns the principal it identifies
+// and true, or false if it is not valid. On success, the principal is
+// stored in the context of the request derived for the handlers that
+// follow in the Chain, and can be retrieved with PrincipalFrom. A missing
+// or invalid key results in ErrUnauthorized with an APIKey challenge
+// naming the header. An error returned by verify is returned unchanged.
+func APIKeyAuth[P any](header string, verify func(ctx context.Context, key string) (P, bool, error)) Guard {
+	challenge := "APIKey header=" + quote(header)
+	f := func(_ http.ResponseWriter, r *http.Request) (*http.Request, error) {
+		key := r.Header.Get(header)
+		if key == "" {
+			return nil, fmt.Errorf("%w: missing credentials", Unauthorized(challenge))
+		}
+		return authenticate(r, challenge, func(ctx context.Context) (P, bool, error) {
+			return verify(ctx, key)
+		})
+	}
+	return Guard{Name: "APIKeyAuth(" + header + ")", Handler: RequestHandlerFuncE(f)}
+}
+
+// authenticate calls verify and returns a request derived from r with the
+// principal it returns.
+func authenticate[P any](r *http.Request, challenge string, verify func(context.Context) (P, bool, error)) (*http.Request, error) {
+	p, ok, err := verify(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid credentials", Unauthorized(challenge))
+	}
+	return withPrincipal(r, p), nil
+}
+
+// Authorize returns a HandlerE that calls allow with the request and the
+// principal of type P stored by one of the authentication handlers, such
+// as BasicAuth, and returns ErrForbidden if allow returns false. Use with
+// Chain or New/Must after an authentication handler, which responds with
+// the WWW-Authenticate challenge to unauthenticated requests. A request
+// without a principal of type P also results in ErrForbidden, as Authorize
+// has no challenge to offer.
+func Authorize[P any](allow func(r *http.Request, p P) bool) Guard {
+	f := func(_ http.ResponseWriter, r *http.Request) error {
+		p, ok := PrincipalFrom[P](r.Context())
+		if !ok || !allow(r, p) {
+			return ErrForbidden
+		}
+		return nil
+	}
+	return Guard{Name: "Authorize", Handler: HandlerFuncE(f)}
+}
+
+// quote returns s as a quoted-string of RFC 9110 section 5.6.4.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,269 @@
+package httpe
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldError describes a problem with a single field of a request. Field
+// is the path of the field, such as "address.street" or "items[2].name".
+// In is the part of the request the field comes from, such as "query" or
+// "path", if not the body. Code is a short machine-readable description of
+// the problem, such as "required", and Message a human-readable one.
+type FieldError struct {
+	Field   string `json:"field"`
+	In      string `json:"in,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// String returns the field error formatted as "field: message".
+func (fe FieldError) String() string { return fe.Field + ": " + fe.Message }
+
+// fieldErrorsString formats the field errors as a message for the
+// StatusError se.
+func fieldErrorsString(se StatusError, fields []FieldError) string {
+	msgs := make([]string, len(fields))
+	for i, fe := range fields {
+		msgs[i] = fe.String()
+	}
+	return se.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+// extendProblemFields adds the field errors to p as the "errors" extension
+// member.
+func extendProblemFields(p *Problem, fields []FieldError) {
+	if p.Extensions == nil {
+		p.Extensions = map[string]interface{}{}
+	}
+	p.Extensions["errors"] = fields
+}
+
+// BindError is the error returned by Bind when values of the request cannot
+// be bound to the fields of a struct. It wraps ErrBadRequest and lists each
+// field in error. The fields are added to the problem details document
+// written by WriteProblem as the "errors" extension member.
+type BindError struct {
+	Fields []FieldError
+}
+
+// Error returns the error message and implements the error interface.
+func (err *BindError) Error() string {
+	return fieldErrorsString(ErrBadRequest, err.Fields)
+}
+
+// Unwrap returns ErrBadRequest.
+func (err *BindError) Unwrap() error { return ErrBadRequest }
+
+// ExtendProblem adds the field errors to p as the "errors" extension member
+// and implements ProblemExtender.
+func (err *BindError) ExtendProblem(p *Problem) {
+	extendProblemFields(p, err.Fields)
+}
+
+// bindSources are the struct tags recognised by Bind, in the order they are
+// looked up on a field.
+var bindSources = []string{"path", "query", "header", "form"}
+
+// Bind decodes values from the request r into the fields of the struct
+// pointed to by v, as directed by the struct tags of the fields:
+//
+//	path:"name"    the path value r.PathValue("name")
+//	query:"name"   the URL query parameter "name"
+//	header:"Name"  the request header "Name"
+//	form:"name"    the form value "name" of a POST, PUT or PATCH body
+//
+// A tag may be followed by ",required" to make the value required. Fields of
+// embedded structs are bound too.
+//
+// Fields may be a string, bool, integer, float, time.Duration, a type that
+// implements encoding.TextUnmarshaler, or a pointer to or slice of these.
+// Pointer fields are left nil if there is no value. Slice fields are set
+// from all values of a query parameter, header or form value.
+//
+// If any value is missing or cannot be parsed, a *BindError is returned
+// listing each field in error. If v is not a pointer to a struct, an error
+// that does not wrap a StatusError is returned.
+func Bind(r *http.Request, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("httpe: cannot bind to %T, not a pointer to a struct", v)
+	}
+	b := &binder{r: r}
+	if err := b.bindStruct(rv.Elem()); err != nil {
+		return err
+	}
+	if len(b.fieldErrs) > 0 {
+		return &BindError{Fields: b.fieldErrs}
+	}
+	return nil
+}
+
+type binder struct {
+	r         *http.Request
+	fieldErrs []FieldError
+}
+
+func (b *binder) bindStruct(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf, fv := t.Field(i), v.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			if err := b.bindStruct(fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		for _, source := range bindSources {
+			if tag, ok := sf.Tag.Lookup(source); ok {
+				if err := b.bindField(fv, source, tag); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (b *binder) bindField(v reflect.Value, source, tag string) error {
+	name, opts, _ := strings.Cut(tag, ",")
+	vals, err := b.values(source, name)
+	if err != nil {
+		return err
+	}
+	if len(vals) == 0 {
+		if opts == "required" {
+			b.addErr(name, source, "required", "required")
+		}
+		return nil
+	}
+	err = setValues(v, vals)
+	if errors.Is(err, errUnsupportedType) {
+		return fmt.Errorf("httpe: cannot bind %s %q: %w", source, name, err)
+	}
+	if err != nil {
+		b.addErr(name, source, "invalid", err.Error())
+	}
+	return nil
+}
+
+func (b *binder) addErr(field, in, code, msg string) {
+	b.fieldErrs = append(b.fieldErrs, FieldError{Field: field, In: in, Code: code, Message: msg})
+}
+
+// values returns the values for name from the source part of the request.
+func (b *binder) values(source, name string) ([]string, error) {
+	switch source {
+	case "path":
+		if s := b.r.PathValue(name); s != "" {
+			return []string{s}, nil
+		}
+		return nil, nil
+	case "query":
+		return b.r.URL.Query()[name], nil
+	case "header":
+		return b.r.Header.Values(name), nil
+	}
+	if err := b.r.ParseForm(); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w", ErrBadRequest, err)
+	}
+	return b.r.PostForm[name], nil
+}
+
+var (
+	errUnsupportedType  = errors.New("unsupported type")
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	durationType        = reflect.TypeFor[time.Duration]()
+)
+
+// setValues sets v from vals. v is set from the first value unless it is a
+// slice, which is set from all values.
+func setValues(v reflect.Value, vals []string) error {
+	if v.Kind() != reflect.Slice || reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return setValue(v, vals[0])
+	}
+	slice := reflect.MakeSlice(v.Type(), len(vals), len(vals))
+	for i, s := range vals {
+		if err := setValue(slice.Index(i), s); err != nil {
+			return err
+		}
+	}
+	v.Set(slice)
+	return nil
+}
+
+// setValue parses s into v according to the type of v.
+func setValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		p := reflect.New(v.Type().Elem())
+		if err := setValue(p.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+	if tu, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := tu.UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("invalid value %q: %w", s, err)
+		}
+		return nil
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	return setBasicValue(v, s)
+}
+
+// setBasicValue parses s into v for the basic kinds of types.
+func setBasicValue(v reflect.Value, s string) error {
+	switch v.Kind() { //nolint:exhaustive // Remaining kinds are unsupported.
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", s)
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("%w %s", errUnsupportedType, v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package httpe
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type Paging struct {
+	Page  int    `query:"page"`
+	Limit *uint8 `query:"limit"`
+}
+
+type bindReq struct {
+	Paging
+	ID       int64         `path:"id,required"`
+	Tags     []string      `query:"tag"`
+	Score    float64       `query:"score"`
+	Verbose  bool          `query:"verbose"`
+	Timeout  time.Duration `query:"timeout"`
+	IP       net.IP        `header:"X-Client-IP"`
+	Accept   []string      `header:"Accept"`
+	Name     string        `form:"name"`
+	Untagged string
+	private  string `query:"private"`
+}
+
+func TestBind(t *testing.T) {
+	body := strings.NewReader("name=kim")
+	r := httptest.NewRequest("POST", "/users/42?page=2&limit=10&tag=a&tag=b&score=1.5&verbose=true&timeout=3s&private=x", body)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Client-IP", "10.0.0.1")
+	r.Header.Add("Accept", "text/html")
+	r.Header.Add("Accept", "application/json")
+	r.SetPathValue("id", "42")
+
+	got := bindReq{}
+	require.NoError(t, Bind(r, &got))
+	limit := uint8(10)
+	want := bindReq{
+		Paging:  Paging{Page: 2, Limit: &limit},
+		ID:      42,
+		Tags:    []string{"a", "b"},
+		Score:   1.5,
+		Verbose: true,
+		Timeout: 3 * time.Second,
+		IP:      net.ParseIP("10.0.0.1"),
+		Accept:  []string{"text/html", "application/json"},
+		Name:    "kim",
+	}
+	require.Equal(t, want, got)
+
+	got = bindReq{}
+	r = httptest.NewRequest("GET", "/users/1", nil)
+	r.SetPathValue("id", "1")
+	require.NoError(t, Bind(r, &got))
+	require.Equal(t, bindReq{ID: 1}, got)
+}
+
+func TestBindErr(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?page=x&limit=300&tag=a&score=high&verbose=maybe&timeout=soon", nil)
+	r.Header.Set("X-Client-IP", "not-an-ip")
+	err := Bind(r, &bindReq{})
+	require.True(t, errors.Is(err, ErrBadRequest))
+	var bindErr *BindError
+	require.True(t, errors.As(err, &bindErr))
+	want := []FieldError{
+		{Field: "page", In: "query", Code: "invalid", Message: `invalid integer "x"`},
+		{Field: "limit", In: "query", Code: "invalid", Message: `invalid unsigned integer "300"`},
+		{Field: "id", In: "path", Code: "required", Message: "required"},
+		{Field: "score", In: "query", Code: "invalid", Message: `invalid number "high"`},
+		{Field: "verbose", In: "query", Code: "invalid", Message: `invalid boolean "maybe"`},
+		{Field: "timeout", In: "query", Code: "invalid", Message: `invalid duration "soon"`},
+		{Field: "X-Client-IP", In: "header", Code: "invalid", Message: `invalid value "not-an-ip": invalid IP address: not-an-ip`},
+	}
+	require.Equal(t, want, bindErr.Fields)
+	require.True(t, strings.HasPrefix(err.Error(), `Bad Request: page: invalid integer "x"; limit: `))
+
+	p := NewProblem(err)
+	require.Equal(t, want, p.Extensions["errors"])
+}
+
+func TestBindSliceErr(t *testing.T) {
+	var v struct {
+		IDs []int `query:"id"`
+	}
+	err := Bind(httptest.NewRequest("GET", "/?id=1&id=x", nil), &v)
+	require.EqualError(t, err, `Bad Request: id: invalid integer "x"`)
+}
+
+func TestBindFormErr(t *testing.T) {
+	var v struct {
+		Name string `form:"name"`
+	}
+	r := httptest.NewRequest("POST", "/", strings.NewReader("name=%zz"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	err := Bind(r, &v)
+	require.True(t, errors.Is(err, ErrBadRequest))
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader("name=kim"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Body = http.MaxBytesReader(httptest.NewRecorder(), r.Body, 2)
+	err = Bind(r, &v)
+	var maxBytesErr *http.MaxBytesError
+	require.True(t, errors.As(err, &maxBytesErr))
+	require.False(t, errors.Is(err, ErrBadRequest))
+}
+
+func TestBindProgrammerErr(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?c=1", nil)
+	var s struct{ S string }
+	for _, v := range []interface{}{nil, s, &[]string{}} {
+		err := Bind(r, v)
+		require.Error(t, err)
+		require.False(t, errors.Is(err, ErrBadRequest))
+	}
+
+	var unsupported struct {
+		C complex64 `query:"c"`
+	}
+	err := Bind(r, &unsupported)
+	require.EqualError(t, err, `httpe: cannot bind query "c": unsupported type complex64`)
+
+	type Unsupported struct {
+		C complex64 `query:"c"`
+	}
+	var embeddedUnsupported struct{ Unsupported }
+	require.Error(t, Bind(r, &embeddedUnsupported))
+
+	var embedded struct {
+		bindReq
+	}
+	r = httptest.NewRequest("GET", "/?page=3", nil)
+	r.SetPathValue("id", "7")
+	require.NoError(t, Bind(r, &embedded))
+	require.Equal(t, 3, embedded.Page)
+	require.Equal(t, int64(7), embedded.ID)
+}
@@ -0,0 +1,108 @@
+package httpe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"sync"
+)
+
+// Classifier maps errors that do not wrap a StatusError to a StatusError so
+// that errors from the standard library or from a domain layer that knows
+// nothing of HTTP are written with an appropriate status code.
+//
+// Rules are added with Is, Func and ClassifyAs and are safe to add while
+// the Classifier is in use. Rules added later take precedence over rules
+// added earlier, so that the default rules can be overridden.
+type Classifier struct {
+	mu    sync.RWMutex
+	rules []func(error) (StatusError, bool)
+}
+
+// DefaultClassifier is the Classifier used by NewHandler unless another is
+// passed with WithClassifier. It is created by NewDefaultClassifier.
+var DefaultClassifier = NewDefaultClassifier()
+
+// NewClassifier returns a Classifier with no rules.
+func NewClassifier() *Classifier {
+	return &Classifier{}
+}
+
+// NewDefaultClassifier returns a Classifier with rules for common errors
+// of the standard library:
+//
+//	fs.ErrNotExist             ErrNotFound
+//	fs.ErrPermission           ErrForbidden
+//	context.DeadlineExceeded   ErrGatewayTimeout
+//	context.Canceled           ErrClientClosedRequest
+//	*json.SyntaxError          ErrBadRequest
+//	*json.UnmarshalTypeError   ErrBadRequest
+//	*http.MaxBytesError        ErrRequestEntityTooLarge
+func NewDefaultClassifier() *Classifier {
+	c := NewClassifier()
+	c.Is(fs.ErrNotExist, ErrNotFound)
+	c.Is(fs.ErrPermission, ErrForbidden)
+	c.Is(context.DeadlineExceeded, ErrGatewayTimeout)
+	c.Is(context.Canceled, ErrClientClosedRequest)
+	ClassifyAs[*json.SyntaxError](c, ErrBadRequest)
+	ClassifyAs[*json.UnmarshalTypeError](c, ErrBadRequest)
+	ClassifyAs[*http.MaxBytesError](c, ErrRequestEntityTooLarge)
+	return c
+}
+
+// Is adds a rule to classify errors that match target with errors.Is as
+// the StatusError se.
+func (c *Classifier) Is(target error, se StatusError) {
+	c.Func(func(err error) (StatusError, bool) {
+		return se, errors.Is(err, target)
+	})
+}
+
+// ClassifyAs adds a rule to c to classify errors that have an error of type
+// E in their tree, as found by errors.As, as the StatusError se.
+func ClassifyAs[E error](c *Classifier, se StatusError) {
+	c.Func(func(err error) (StatusError, bool) {
+		var target E
+		return se, errors.As(err, &target)
+	})
+}
+
+// Func adds a rule that classifies errors for which f returns true as the
+// StatusError returned by f.
+func (c *Classifier) Func(f func(error) (StatusError, bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rules = append(c.rules, f)
+}
+
+// Classify returns err unchanged if it is nil, already wraps a StatusError
+// or matches no rule of c. Otherwise it returns an error that wraps both
+// the StatusError of the last matching rule and err. The message of the
+// returned error is that of the StatusError alone, so that the details of
+// err are not written to the client even for a client error. Classify on
+// a nil Classifier returns err unchanged.
+func (c *Classifier) Classify(err error) error {
+	var sErr StatusError
+	if c == nil || err == nil || errors.As(err, &sErr) {
+		return err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if se, ok := c.rules[i](err); ok {
+			return &classifiedError{StatusError: se, err: err}
+		}
+	}
+	return err
+}
+
+// classifiedError is an error classified as a StatusError by a Classifier.
+type classifiedError struct {
+	StatusError
+	err error
+}
+
+// Unwrap returns the StatusError and the classified error.
+func (e *classifiedError) Unwrap() []error { return []error{e.StatusError, e.err} }
@@ -0,0 +1,100 @@
+package httpe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errNoStock = errors.New("out of stock")
+
+type domainErr struct{ msg string }
+
+func (e *domainErr) Error() string { return e.msg }
+
+func TestDefaultClassifier(t *testing.T) {
+	_, openErr := os.Open("/does/not/exist")
+	var syntaxErr *json.SyntaxError
+	require.True(t, errors.As(json.Unmarshal([]byte("{"), &struct{}{}), &syntaxErr))
+	var typeErr error = &json.UnmarshalTypeError{Value: "string"}
+	maxBytesErr := &http.MaxBytesError{Limit: 10}
+
+	tests := map[error]StatusError{
+		openErr:                  ErrNotFound,
+		fs.ErrPermission:         ErrForbidden,
+		context.DeadlineExceeded: ErrGatewayTimeout,
+		context.Canceled:         ErrClientClosedRequest,
+		syntaxErr:                ErrBadRequest,
+		typeErr:                  ErrBadRequest,
+		maxBytesErr:              ErrRequestEntityTooLarge,
+	}
+	for err, want := range tests {
+		wrapped := fmt.Errorf("wrapped: %w", err)
+		got := DefaultClassifier.Classify(wrapped)
+		require.True(t, errors.Is(got, want), err)
+		require.True(t, errors.Is(got, err), err)
+		require.Equal(t, want.Error(), got.Error(), err)
+		require.Equal(t, want.Code(), StatusCode(got), err)
+	}
+
+	errOther := errors.New("other")
+	require.Equal(t, errOther, DefaultClassifier.Classify(errOther))
+	require.Nil(t, DefaultClassifier.Classify(nil))
+	err := fmt.Errorf("%w: %w", ErrGone, os.ErrNotExist)
+	require.Equal(t, err, DefaultClassifier.Classify(err))
+}
+
+func TestClassifier(t *testing.T) {
+	c := NewDefaultClassifier()
+	c.Is(errNoStock, ErrConflict)
+	ClassifyAs[*domainErr](c, ErrUnprocessableEntity)
+	c.Func(func(err error) (StatusError, bool) {
+		return ErrGone, strings.Contains(err.Error(), "gone")
+	})
+
+	require.True(t, errors.Is(c.Classify(errNoStock), ErrConflict))
+	require.True(t, errors.Is(c.Classify(&domainErr{"bad"}), ErrUnprocessableEntity))
+	require.True(t, errors.Is(c.Classify(fmt.Errorf("gone: %w", os.ErrNotExist)), ErrGone))
+	require.True(t, errors.Is(c.Classify(os.ErrNotExist), ErrNotFound))
+
+	var nilClassifier *Classifier
+	require.Equal(t, errNoStock, nilClassifier.Classify(errNoStock))
+	require.Equal(t, errNoStock, NewClassifier().Classify(errNoStock))
+}
+
+func TestClientClosedRequest(t *testing.T) {
+	require.Equal(t, "Client Closed Request", ErrClientClosedRequest.Error())
+	require.True(t, ErrClientClosedRequest.IsClientError())
+}
+
+func TestNewHandlerClassify(t *testing.T) {
+	var observed error
+	obs := func(_ *http.Request, err error, _ int) { observed = err }
+	h := Must(func(http.ResponseWriter, *http.Request) error {
+		_, err := os.Open("/secret/path")
+		return err
+	}, obs)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, "Not Found\n", w.Body.String())
+	var pathErr *fs.PathError
+	require.True(t, errors.As(observed, &pathErr))
+	require.False(t, errors.Is(observed, ErrNotFound))
+
+	h = Must(func(http.ResponseWriter, *http.Request) error {
+		return os.ErrNotExist
+	}, WithClassifier(nil))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+}
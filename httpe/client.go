@@ -0,0 +1,143 @@
+package httpe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// maxErrBody is the maximum number of bytes of an error response body
+// read by CheckResponse.
+const maxErrBody = 64 << 10
+
+// ResponseError is the error returned by CheckResponse for a response with
+// a status code of 400 or above. It wraps the StatusError of the response
+// status code so that errors.Is can be used to check for sentinel values
+// such as ErrNotFound as if the error had been returned by a HandlerE of
+// the remote service.
+//
+// The headers of the response are not written by the ErrWriter if a
+// ResponseError is returned by a HandlerE, as they belong to the remote
+// service.
+type ResponseError struct {
+	StatusError
+	// Header is the header of the response.
+	Header http.Header
+	// Problem is the problem details document of the response body, if it
+	// has one, otherwise nil.
+	Problem *Problem
+
+	detail string
+}
+
+// Error returns the error message and implements the error interface. The
+// message is the status text followed by the detail of the problem
+// details document or the first line of a plain text response body.
+func (err *ResponseError) Error() string {
+	title := err.StatusError.Error()
+	switch {
+	case err.detail == "" || err.detail == title:
+		return title
+	case strings.HasPrefix(err.detail, title+": "):
+		return err.detail
+	}
+	return title + ": " + err.detail
+}
+
+// Unwrap returns the StatusError of the response status code.
+func (err *ResponseError) Unwrap() error { return err.StatusError }
+
+// CheckResponse returns a *ResponseError if resp has a status code of 400
+// or above, and nil otherwise.
+//
+// A response body with the application/problem+json content type, or the
+// application/json content type and a title or status member, is parsed
+// as the Problem of the error. The first line of a text/plain response
+// body, such as one written by WriteSafeErr, becomes the detail of the
+// error message.
+//
+// The response body is not consumed: the bytes read from it are put back
+// so that the caller can still read and must close the body.
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+	err := &ResponseError{StatusError: StatusError(resp.StatusCode), Header: resp.Header}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrBody))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), resp.Body), resp.Body}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/problem+json", "application/json":
+		p := &Problem{}
+		if json.Unmarshal(b, p) == nil && (mediaType == "application/problem+json" || p.Title != "" || p.Status != 0) {
+			err.Problem = p
+			err.detail = p.Detail
+		}
+	case "text/plain":
+		line, _, _ := strings.Cut(string(b), "\n")
+		err.detail = strings.TrimSpace(line)
+	}
+	return err
+}
+
+// Client is an HTTP client that returns a *ResponseError for responses
+// with a status code of 400 or above, so that the errors of a remote
+// service built with httpe can be handled like local ones.
+type Client struct {
+	hc *http.Client
+}
+
+// NewClient returns a new Client sending requests with hc, or with
+// http.DefaultClient if hc is nil.
+func NewClient(hc *http.Client) *Client {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	return &Client{hc: hc}
+}
+
+// Do sends the request req and returns the response. If the response has
+// a status code of 400 or above, its body is closed and the error returned
+// by CheckResponse is returned with a nil response. Otherwise, the caller
+// must close the response body.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckResponse(resp); err != nil {
+		resp.Body.Close() //nolint:errcheck,gosec
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DoJSON sends the request req, accepting a JSON response, and decodes the
+// JSON response body into v. If v is nil or the response has no content,
+// the body is discarded. Errors are returned as by Do.
+func (c *Client) DoJSON(req *http.Request, v interface{}) error {
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json, application/problem+json")
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck,gosec
+	if v == nil || resp.StatusCode == http.StatusNoContent {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("httpe: cannot decode response: %w", err)
+	}
+	return nil
+}
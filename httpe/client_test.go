@@ -0,0 +1,134 @@
+package httpe
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.Handle("/problem", Must(func(http.ResponseWriter, *http.Request) error {
+		return &ValidationError{Fields: []FieldError{{Field: "name", Code: "required", Message: "required"}}}
+	}, WriteProblem))
+	mux.Handle("/text", Must(func(http.ResponseWriter, *http.Request) error {
+		return fmt.Errorf("%w: no such user", ErrNotFound)
+	}))
+	mux.Handle("/json", Must(func(http.ResponseWriter, *http.Request) error {
+		return ErrConflict
+	}, WriteJSONErr))
+	mux.HandleFunc("/other", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprint(w, `{"error": "upstream"}`)
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"name": "kim"}`)
+	})
+	mux.HandleFunc("/invalid", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"name": `)
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestCheckResponse(t *testing.T) {
+	s := newTestServer(t)
+	tests := map[string]struct {
+		target  error
+		msg     string
+		problem bool
+		body    string
+	}{
+		"/problem": {ErrUnprocessableEntity, "Unprocessable Entity: name: required", true, ""},
+		"/text":    {ErrNotFound, "Not Found: no such user", false, "Not Found: no such user\n"},
+		"/json":    {ErrConflict, "Conflict", true, ""},
+		"/other":   {ErrBadGateway, "Bad Gateway", false, `{"error": "upstream"}`},
+	}
+	for path, tc := range tests {
+		resp, err := http.Get(s.URL + path) //nolint:noctx
+		require.NoError(t, err)
+		err = CheckResponse(resp)
+		require.True(t, errors.Is(err, tc.target), path)
+		require.Equal(t, tc.msg, err.Error(), path)
+		var respErr *ResponseError
+		require.True(t, errors.As(err, &respErr), path)
+		require.Equal(t, tc.problem, respErr.Problem != nil, path)
+		require.Equal(t, resp.Header, respErr.Header, path)
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		if tc.body != "" {
+			require.Equal(t, tc.body, string(b), path)
+		}
+	}
+
+	resp, err := http.Get(s.URL + "/problem") //nolint:noctx
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var respErr *ResponseError
+	require.True(t, errors.As(CheckResponse(resp), &respErr))
+	want := []interface{}{map[string]interface{}{"field": "name", "code": "required", "message": "required"}}
+	require.Equal(t, want, respErr.Problem.Extensions["errors"])
+	require.Equal(t, http.StatusUnprocessableEntity, respErr.Problem.Status)
+
+	require.NoError(t, CheckResponse(&http.Response{StatusCode: http.StatusNotModified}))
+}
+
+func TestResponseErrorMessage(t *testing.T) {
+	tests := map[string]string{
+		"":                      "Forbidden",
+		"Forbidden":             "Forbidden",
+		"Forbidden: not yours":  "Forbidden: not yours",
+		"You may not see this.": "Forbidden: You may not see this.",
+	}
+	for detail, want := range tests {
+		err := &ResponseError{StatusError: ErrForbidden, detail: detail}
+		require.Equal(t, want, err.Error(), detail)
+	}
+}
+
+func TestClient(t *testing.T) {
+	s := newTestServer(t)
+	c := NewClient(nil)
+
+	req := httptest.NewRequest("GET", s.URL+"/ok", nil)
+	req.RequestURI = ""
+	var v struct{ Name string }
+	require.NoError(t, c.DoJSON(req, &v))
+	require.Equal(t, "kim", v.Name)
+	require.Equal(t, "application/json, application/problem+json", req.Header.Get("Accept"))
+
+	req.URL.Path = "/text"
+	err := c.DoJSON(req, &v)
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	req.URL.Path = "/invalid"
+	err = c.DoJSON(req, &v)
+	require.True(t, strings.HasPrefix(err.Error(), "httpe: cannot decode response: "), err)
+
+	req.URL.Path = "/empty"
+	require.NoError(t, c.DoJSON(req, &v))
+	req.URL.Path = "/ok"
+	require.NoError(t, c.DoJSON(req, nil))
+
+	c = NewClient(&http.Client{})
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	req.URL.Host = "invalid host"
+	_, err = c.Do(req)
+	require.Error(t, err)
+}
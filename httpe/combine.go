@@ -0,0 +1,84 @@
+package httpe
+
+import (
+	"errors"
+	"net/http"
+)
+
+// First returns a HandlerE that executes each of the HandlerE parameters
+// sequentially until one of them returns nil, such as a Chain of
+// alternative authentication handlers. An error wrapping ErrHandled also
+// stops First and is returned, as the response is complete. If all of them
+// return another error, the error of the last one is returned. Handlers
+// other than the last should not write to the response before returning an
+// error. The request derived by the handler that returns nil is passed on
+// in a Chain.
+func First(he ...HandlerE) HandlerE {
+	f := func(w http.ResponseWriter, r *http.Request) (*http.Request, error) {
+		var err error
+		for _, h := range he {
+			var r2 *http.Request
+			if r2, err = serve(h, w, r); err == nil {
+				return r2, nil
+			}
+			if errors.Is(err, ErrHandled) {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+	return RequestHandlerFuncE(f)
+}
+
+// If returns a HandlerE that executes the HandlerE parameters as a Chain
+// if pred returns true for the request, and returns nil otherwise. The
+// request derived by the chain is passed on in a Chain.
+func If(pred func(*http.Request) bool, he ...HandlerE) HandlerE {
+	h := Chain(he...)
+	f := func(w http.ResponseWriter, r *http.Request) (*http.Request, error) {
+		if !pred(r) {
+			return nil, nil
+		}
+		return serve(h, w, r)
+	}
+	return RequestHandlerFuncE(f)
+}
+
+// Unless returns a HandlerE that executes the HandlerE parameters as a
+// Chain if pred returns false for the request, and returns nil otherwise.
+func Unless(pred func(*http.Request) bool, he ...HandlerE) HandlerE {
+	return If(func(r *http.Request) bool { return !pred(r) }, he...)
+}
+
+// Catch returns a HandlerE that calls h and, if h returns an error that
+// matches target according to errors.Is, calls f with the error and
+// returns the result of f instead. f can recover from the error by
+// writing a response and returning nil, or rewrite it by returning
+// another error. Other errors are returned unchanged, and if h returns
+// nil, the request it derives is passed on in a Chain.
+func Catch(h HandlerE, target error, f func(w http.ResponseWriter, r *http.Request, err error) error) HandlerE {
+	c := func(w http.ResponseWriter, r *http.Request) (*http.Request, error) {
+		r2, err := serve(h, w, r)
+		if err != nil && errors.Is(err, target) {
+			return nil, f(w, r, err)
+		}
+		return r2, err
+	}
+	return RequestHandlerFuncE(c)
+}
+
+// CatchAs returns a HandlerE that calls h and, if h returns an error that
+// has an error of type E in its tree according to errors.As, calls f with
+// that error and returns the result of f instead. Other errors are
+// returned unchanged. See Catch.
+func CatchAs[E error](h HandlerE, f func(w http.ResponseWriter, r *http.Request, err E) error) HandlerE {
+	c := func(w http.ResponseWriter, r *http.Request) (*http.Request, error) {
+		r2, err := serve(h, w, r)
+		var target E
+		if err != nil && errors.As(err, &target) {
+			return nil, f(w, r, target)
+		}
+		return r2, err
+	}
+	return RequestHandlerFuncE(c)
+}
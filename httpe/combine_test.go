@@ -0,0 +1,139 @@
+package httpe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func errHandler(err error) HandlerFuncE {
+	return func(http.ResponseWriter, *http.Request) error { return err }
+}
+
+func TestFirst(t *testing.T) {
+	var calls []string
+	record := func(name string, err error) HandlerFuncE {
+		return func(http.ResponseWriter, *http.Request) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+	w, r := httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)
+
+	h := First(record("cookie", ErrUnauthorized), record("token", nil), record("never", nil))
+	require.NoError(t, h.ServeHTTPe(w, r))
+	require.Equal(t, []string{"cookie", "token"}, calls)
+
+	calls = nil
+	h = First(record("cookie", ErrUnauthorized), record("token", ErrForbidden))
+	require.Equal(t, ErrForbidden, h.ServeHTTPe(w, r))
+	require.Equal(t, []string{"cookie", "token"}, calls)
+
+	require.NoError(t, First().ServeHTTPe(w, r))
+}
+
+func TestFirstHandled(t *testing.T) {
+	body := HandlerFuncE(func(w http.ResponseWriter, _ *http.Request) error {
+		fmt.Fprint(w, "body")
+		return nil
+	})
+	h := Must(First(CORS{AllowedOrigins: []string{"https://example.com"}}, body))
+	r := httptest.NewRequest("OPTIONS", "/", nil)
+	r.Header.Set("Origin", "https://example.com")
+	r.Header.Set("Access-Control-Request-Method", "GET")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Empty(t, w.Body.String())
+}
+
+func TestIfUnless(t *testing.T) {
+	isPost := func(r *http.Request) bool { return r.Method == http.MethodPost }
+	get, post := httptest.NewRequest("GET", "/", nil), httptest.NewRequest("POST", "/", nil)
+	w := httptest.NewRecorder()
+
+	h := If(isPost, errHandler(nil), ContentTypeJSON)
+	require.NoError(t, h.ServeHTTPe(w, get))
+	err := h.ServeHTTPe(w, httptest.NewRequest("POST", "/", http.NoBody))
+	require.NoError(t, err)
+	post.Header.Set("Content-Type", "text/plain")
+	post.ContentLength = 1
+	require.True(t, errors.Is(h.ServeHTTPe(w, post), ErrUnsupportedMediaType))
+
+	h = Unless(isPost, errHandler(ErrMethodNotAllowed))
+	require.Equal(t, ErrMethodNotAllowed, h.ServeHTTPe(w, get))
+	require.NoError(t, h.ServeHTTPe(w, post))
+}
+
+func TestCatch(t *testing.T) {
+	notFound := errHandler(fmt.Errorf("%w: no such page", ErrNotFound))
+	fallback := func(w http.ResponseWriter, _ *http.Request, err error) error {
+		fmt.Fprint(w, "fallback for ", err)
+		return nil
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+
+	w := httptest.NewRecorder()
+	require.NoError(t, Catch(notFound, ErrNotFound, fallback).ServeHTTPe(w, r))
+	require.Equal(t, "fallback for Not Found: no such page", w.Body.String())
+
+	w = httptest.NewRecorder()
+	require.Equal(t, ErrGone, Catch(errHandler(ErrGone), ErrNotFound, fallback).ServeHTTPe(w, r))
+	require.NoError(t, Catch(errHandler(nil), ErrNotFound, fallback).ServeHTTPe(w, r))
+	require.Empty(t, w.Body.String())
+
+	rewrite := func(_ http.ResponseWriter, _ *http.Request, err error) error {
+		return fmt.Errorf("%w: %w", ErrGone, err)
+	}
+	err := Catch(notFound, ErrNotFound, rewrite).ServeHTTPe(w, r)
+	require.Equal(t, http.StatusGone, StatusCode(err))
+}
+
+func TestCatchAs(t *testing.T) {
+	invalid := errHandler(fmt.Errorf("decoding: %w", &BindError{Fields: []FieldError{{Field: "id", Message: "invalid"}}}))
+	toValidation := func(_ http.ResponseWriter, _ *http.Request, err *BindError) error {
+		return &ValidationError{Fields: err.Fields}
+	}
+	r, w := httptest.NewRequest("GET", "/", nil), httptest.NewRecorder()
+
+	err := CatchAs(invalid, toValidation).ServeHTTPe(w, r)
+	require.Equal(t, &ValidationError{Fields: []FieldError{{Field: "id", Message: "invalid"}}}, err)
+
+	require.Equal(t, ErrNotFound, CatchAs(errHandler(ErrNotFound), toValidation).ServeHTTPe(w, r))
+	require.NoError(t, CatchAs(errHandler(nil), toValidation).ServeHTTPe(w, r))
+}
+
+func TestCombinatorsDerivedRequest(t *testing.T) {
+	key := NewContextKey[string]("via")
+	via := func(name string) RequestHandlerFuncE {
+		return func(_ http.ResponseWriter, r *http.Request) (*http.Request, error) {
+			return key.WithValue(r, name), nil
+		}
+	}
+	var got string
+	final := HandlerFuncE(func(_ http.ResponseWriter, r *http.Request) error {
+		got, _ = key.Value(r.Context())
+		return nil
+	})
+	always := func(*http.Request) bool { return true }
+	tests := map[string]HandlerE{
+		"first":   First(errHandler(ErrUnauthorized), via("first")),
+		"if":      If(always, via("if")),
+		"unless":  Unless(always, via("never")),
+		"catch":   Catch(via("catch"), ErrNotFound, nil),
+		"catchAs": CatchAs[*BindError](via("catchAs"), nil),
+	}
+	for name, h := range tests {
+		got = ""
+		Must(h, final).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		want := name
+		if name == "unless" {
+			want = ""
+		}
+		require.Equal(t, want, got, name)
+	}
+}
@@ -0,0 +1,185 @@
+package httpe
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Validators are the validators of the current representation of a
+// resource, used to evaluate the preconditions of a conditional request as
+// described in RFC 9110 section 13. ETag is an entity tag including its
+// quotes and optional weak prefix, such as `"v1"` or `W/"v1"`. Either may
+// be left as its zero value if unknown. If both are zero, the resource is
+// taken to have no current representation.
+type Validators struct {
+	ETag         string
+	LastModified time.Time
+}
+
+func (v Validators) exists() bool {
+	return v.ETag != "" || !v.LastModified.IsZero()
+}
+
+// ETag returns a strong entity tag for the content b.
+func ETag(b []byte) string {
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Conditional returns a HandlerE that evaluates the preconditions of a
+// request against the Validators of the requested resource returned by f,
+// following the rules of RFC 9110 section 13.2.2. Use with Chain or
+// New/Must, before the handler that serves or modifies the resource.
+//
+// For a GET or HEAD request, the ETag and Last-Modified headers of the
+// response are set from the Validators. If the representation has not
+// been modified according to If-None-Match or If-Modified-Since, a 304 Not
+// Modified response is written and ErrHandled returned to stop the Chain.
+//
+// A failed If-Match or If-Unmodified-Since precondition, or a matching
+// If-None-Match on a request other than GET or HEAD, results in
+// ErrPreconditionFailed. An error returned by f is returned unchanged.
+func Conditional(f func(*http.Request) (Validators, error)) Guard {
+	return Guard{Name: "Conditional", Handler: conditional(f, false)}
+}
+
+// ConditionalRequired returns a HandlerE like Conditional that also
+// returns ErrPreconditionRequired for a request with a method other than
+// GET, HEAD, OPTIONS or TRACE that has neither an If-Match nor an
+// If-Unmodified-Since header. It is used to enforce optimistic concurrency
+// control, so that a client cannot modify a resource without stating the
+// representation it expects to modify.
+func ConditionalRequired(f func(*http.Request) (Validators, error)) Guard {
+	return Guard{Name: "ConditionalRequired", Handler: conditional(f, true)}
+}
+
+func conditional(f func(*http.Request) (Validators, error), require bool) HandlerFuncE {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if require && !isSafeMethod(r.Method) && r.Header.Get("If-Match") == "" && r.Header.Get("If-Unmodified-Since") == "" {
+			return fmt.Errorf("%w: If-Match or If-Unmodified-Since header required", ErrPreconditionRequired)
+		}
+		v, err := f(r)
+		if err != nil {
+			return err
+		}
+		getOrHead := r.Method == http.MethodGet || r.Method == http.MethodHead
+		if getOrHead {
+			setValidatorHeaders(w.Header(), v)
+		}
+		switch evaluatePreconditions(r, v) {
+		case http.StatusPreconditionFailed:
+			return ErrPreconditionFailed
+		case http.StatusNotModified:
+			if !getOrHead {
+				return ErrPreconditionFailed
+			}
+			w.WriteHeader(http.StatusNotModified)
+			return ErrHandled
+		}
+		return nil
+	}
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func setValidatorHeaders(h http.Header, v Validators) {
+	if v.ETag != "" {
+		h.Set("ETag", v.ETag)
+	}
+	if !v.LastModified.IsZero() {
+		h.Set("Last-Modified", v.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// evaluatePreconditions evaluates the conditional headers of r against v
+// in the order given by RFC 9110 section 13.2.2. It returns 412 if a
+// precondition failed, 304 if the representation has not been modified
+// and 0 otherwise.
+func evaluatePreconditions(r *http.Request, v Validators) int {
+	if im := r.Header.Get("If-Match"); im != "" {
+		if !matchETag(im, v, strongMatch) {
+			return http.StatusPreconditionFailed
+		}
+	} else if t, ok := headerTime(r, "If-Unmodified-Since"); ok && !v.LastModified.IsZero() {
+		if v.LastModified.Truncate(time.Second).After(t) {
+			return http.StatusPreconditionFailed
+		}
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if matchETag(inm, v, weakMatch) {
+			return http.StatusNotModified
+		}
+	} else if t, ok := headerTime(r, "If-Modified-Since"); ok && !v.LastModified.IsZero() {
+		if (r.Method == http.MethodGet || r.Method == http.MethodHead) && !v.LastModified.Truncate(time.Second).After(t) {
+			return http.StatusNotModified
+		}
+	}
+	return 0
+}
+
+func headerTime(r *http.Request, name string) (time.Time, bool) {
+	t, err := http.ParseTime(r.Header.Get(name))
+	return t, err == nil
+}
+
+// matchETag returns true if the If-Match or If-None-Match header value
+// matches the entity tag of v using the comparison function match. "*"
+// matches any current representation.
+func matchETag(header string, v Validators, match func(a, b string) bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return v.exists()
+	}
+	if v.ETag == "" {
+		return false
+	}
+	for _, etag := range parseETags(header) {
+		if match(etag, v.ETag) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseETags parses a comma separated list of entity tags, allowing commas
+// within the quoted opaque tags.
+func parseETags(s string) []string {
+	var etags []string
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == "" {
+			return etags
+		}
+		start := 0
+		if strings.HasPrefix(s, "W/") {
+			start = 2
+		}
+		if len(s) <= start || s[start] != '"' {
+			return etags
+		}
+		end := strings.IndexByte(s[start+1:], '"')
+		if end < 0 {
+			return etags
+		}
+		end += start + 2
+		etags = append(etags, s[:end])
+		s = s[end:]
+	}
+}
+
+func strongMatch(a, b string) bool {
+	return a == b && !strings.HasPrefix(a, "W/")
+}
+
+func weakMatch(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
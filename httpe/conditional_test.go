@@ -0,0 +1,134 @@
+package httpe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	lastModified = time.Date(2020, 5, 1, 12, 0, 0, 500, time.UTC)
+	before       = lastModified.Add(-time.Hour).Format(http.TimeFormat)
+	after        = lastModified.Add(time.Hour).Format(http.TimeFormat)
+	at           = lastModified.Format(http.TimeFormat)
+)
+
+func validators(etag string, lm time.Time) func(*http.Request) (Validators, error) {
+	return func(*http.Request) (Validators, error) {
+		return Validators{ETag: etag, LastModified: lm}, nil
+	}
+}
+
+func TestConditional(t *testing.T) {
+	tests := []struct {
+		method  string
+		header  string
+		value   string
+		etag    string
+		want    int
+		wantErr error
+	}{
+		{"GET", "", "", `"v1"`, 200, nil},
+		{"GET", "If-None-Match", `"v1"`, `"v1"`, 304, ErrHandled},
+		{"GET", "If-None-Match", `W/"v1"`, `"v1"`, 304, ErrHandled},
+		{"HEAD", "If-None-Match", `"v0", "v1"`, `W/"v1"`, 304, ErrHandled},
+		{"GET", "If-None-Match", `"v0", "a,b"`, `"a,b"`, 304, ErrHandled},
+		{"GET", "If-None-Match", `"v0"`, `"v1"`, 200, nil},
+		{"GET", "If-None-Match", `*`, `"v1"`, 304, ErrHandled},
+		{"GET", "If-None-Match", `*`, ``, 200, nil},
+		{"GET", "If-None-Match", `"v1"`, ``, 200, nil},
+		{"GET", "If-None-Match", `v1, "v1"`, `"v1"`, 200, nil},
+		{"GET", "If-None-Match", `W/`, `"v1"`, 200, nil},
+		{"GET", "If-None-Match", `"v1`, `"v1"`, 200, nil},
+		{"PUT", "If-None-Match", `*`, `"v1"`, 200, ErrPreconditionFailed},
+		{"GET", "If-Modified-Since", at, `"v1"`, 304, ErrHandled},
+		{"GET", "If-Modified-Since", after, `"v1"`, 304, ErrHandled},
+		{"GET", "If-Modified-Since", before, `"v1"`, 200, nil},
+		{"GET", "If-Modified-Since", "not a date", `"v1"`, 200, nil},
+		{"POST", "If-Modified-Since", at, `"v1"`, 200, nil},
+		{"PUT", "If-Match", `"v1"`, `"v1"`, 200, nil},
+		{"PUT", "If-Match", `W/"v1"`, `W/"v1"`, 200, ErrPreconditionFailed},
+		{"PUT", "If-Match", `"v0"`, `"v1"`, 200, ErrPreconditionFailed},
+		{"PUT", "If-Match", `*`, `"v1"`, 200, nil},
+		{"PUT", "If-Match", `*`, ``, 200, ErrPreconditionFailed},
+		{"PUT", "If-Match", `"v1"`, ``, 200, ErrPreconditionFailed},
+		{"PUT", "If-Unmodified-Since", at, `"v1"`, 200, nil},
+		{"PUT", "If-Unmodified-Since", before, `"v1"`, 200, ErrPreconditionFailed},
+	}
+	for i, tc := range tests {
+		lm := lastModified
+		if tc.etag == "" {
+			lm = time.Time{}
+		}
+		h := Conditional(validators(tc.etag, lm))
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(tc.method, "/", nil)
+		if tc.header != "" {
+			r.Header.Set(tc.header, tc.value)
+		}
+		err := h.ServeHTTPe(w, r)
+		require.Equal(t, tc.wantErr, err, "%d: %v", i, tc)
+		require.Equal(t, tc.want, w.Code, "%d: %v", i, tc)
+	}
+}
+
+func TestConditionalHeaders(t *testing.T) {
+	h := Conditional(validators(`"v1"`, lastModified))
+	w := httptest.NewRecorder()
+	require.NoError(t, h.ServeHTTPe(w, httptest.NewRequest("GET", "/", nil)))
+	require.Equal(t, `"v1"`, w.Header().Get("ETag"))
+	require.Equal(t, at, w.Header().Get("Last-Modified"))
+
+	w = httptest.NewRecorder()
+	require.NoError(t, h.ServeHTTPe(w, httptest.NewRequest("PUT", "/", nil)))
+	require.Empty(t, w.Header())
+}
+
+func TestConditionalRequired(t *testing.T) {
+	h := ConditionalRequired(validators(`"v1"`, lastModified))
+	r := httptest.NewRequest("PUT", "/", nil)
+	err := h.ServeHTTPe(httptest.NewRecorder(), r)
+	require.True(t, errors.Is(err, ErrPreconditionRequired))
+
+	r.Header.Set("If-Match", `"v1"`)
+	require.NoError(t, h.ServeHTTPe(httptest.NewRecorder(), r))
+
+	r = httptest.NewRequest("PATCH", "/", nil)
+	r.Header.Set("If-Unmodified-Since", at)
+	require.NoError(t, h.ServeHTTPe(httptest.NewRecorder(), r))
+
+	for _, method := range []string{"GET", "HEAD", "OPTIONS", "TRACE"} {
+		require.NoError(t, h.ServeHTTPe(httptest.NewRecorder(), httptest.NewRequest(method, "/", nil)))
+	}
+}
+
+func TestConditionalErr(t *testing.T) {
+	h := Conditional(func(*http.Request) (Validators, error) {
+		return Validators{}, ErrNotFound
+	})
+	err := h.ServeHTTPe(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, ErrNotFound, err)
+}
+
+func TestETag(t *testing.T) {
+	require.Equal(t, `"b94d27b9934d3e08a52e52d7da7dabfa"`, ETag([]byte("hello world")))
+}
+
+func TestErrHandled(t *testing.T) {
+	observed := false
+	h := Must(Conditional(validators(`"v1"`, time.Time{})), func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "body")
+	}, func(*http.Request, error, int) { observed = true })
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-None-Match", `"v1"`)
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusNotModified, w.Code)
+	require.Empty(t, w.Body.String())
+	require.False(t, observed)
+}
@@ -0,0 +1,52 @@
+package httpe
+
+import (
+	"context"
+	"net/http"
+)
+
+// ContextKey is a typed key for a request context value of type T. A
+// handler in a Chain can pass a value to the handlers that follow it by
+// deriving a request with WithValue from a RequestHandlerE:
+//
+//	var userKey = httpe.NewContextKey[*User]("user")
+//
+//	func loadUser(w http.ResponseWriter, r *http.Request) (*http.Request, error) {
+//		user, err := lookupUser(r)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return userKey.WithValue(r, user), nil
+//	}
+//
+// Each key returned by NewContextKey is distinct, even for the same name.
+type ContextKey[T any] struct {
+	name string
+}
+
+// NewContextKey returns a new ContextKey for values of type T. The name is
+// only used for debugging.
+func NewContextKey[T any](name string) *ContextKey[T] {
+	return &ContextKey[T]{name: name}
+}
+
+// String returns the name of the key.
+func (k *ContextKey[T]) String() string { return "httpe.ContextKey(" + k.name + ")" }
+
+// Value returns the value of the key in ctx and true, or the zero value
+// of T and false if ctx has no value for the key.
+func (k *ContextKey[T]) Value(ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(k).(T)
+	return v, ok
+}
+
+// WithContext returns a copy of ctx with the value v for the key.
+func (k *ContextKey[T]) WithContext(ctx context.Context, v T) context.Context {
+	return context.WithValue(ctx, k, v)
+}
+
+// WithValue returns a shallow copy of r with the value v for the key in
+// its context.
+func (k *ContextKey[T]) WithValue(r *http.Request, v T) *http.Request {
+	return r.WithContext(k.WithContext(r.Context(), v))
+}
@@ -0,0 +1,26 @@
+package httpe
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextKey(t *testing.T) {
+	k1, k2 := NewContextKey[int]("id"), NewContextKey[int]("id")
+	require.Equal(t, "httpe.ContextKey(id)", k1.String())
+
+	ctx := k1.WithContext(context.Background(), 42)
+	v, ok := k1.Value(ctx)
+	require.True(t, ok)
+	require.Equal(t, 42, v)
+	_, ok = k2.Value(ctx)
+	require.False(t, ok)
+
+	r := k2.WithValue(httptest.NewRequest("GET", "/", nil), 7)
+	v, ok = k2.Value(r.Context())
+	require.True(t, ok)
+	require.Equal(t, 7, v)
+}
@@ -0,0 +1,142 @@
+package httpe
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS is a HandlerE that implements Cross-Origin Resource Sharing as
+// described in the Fetch standard. Use it as the first element of a Chain
+// or New/Must, so that it also sees preflight requests.
+//
+// A request without an Origin header is passed on unchanged. A request
+// from an origin that is not allowed results in ErrForbidden. For an
+// allowed origin, the Access-Control-Allow-Origin header and related
+// headers are set and the request is passed on to the next handler.
+//
+// A preflight request, an OPTIONS request with an
+// Access-Control-Request-Method header, is answered with a 204 No Content
+// response and ErrHandled returned to stop the Chain. A preflight request
+// for a method or headers that are not allowed results in ErrForbidden.
+type CORS struct {
+	// AllowedOrigins are the origins allowed to make requests, such as
+	// "https://example.com". An origin may contain a single "*" wildcard,
+	// such as "https://*.example.com". The origin "*" allows any origin,
+	// unless AllowCredentials is set, as the Fetch standard does not allow
+	// credentials for any origin.
+	AllowedOrigins []string
+	// AllowedMethods are the methods allowed in requests. If empty, GET,
+	// HEAD and POST are allowed.
+	AllowedMethods []string
+	// AllowedHeaders are the request headers allowed in requests, other
+	// than the CORS-safelisted request headers. The header "*" allows any
+	// header.
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers that scripts are allowed to
+	// read, other than the CORS-safelisted response headers.
+	ExposedHeaders []string
+	// AllowCredentials allows requests with credentials, such as cookies.
+	// It requires the origins to be listed in AllowedOrigins explicitly:
+	// the origin "*" is ignored if AllowCredentials is set.
+	AllowCredentials bool
+	// MaxAge is how long the result of a preflight request may be cached.
+	// It is rounded up to whole seconds. If zero, the header is not set.
+	MaxAge time.Duration
+}
+
+var defaultCORSMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
+// ServeHTTPe sets the CORS response headers for a request from an allowed
+// origin and answers preflight requests.
+func (c CORS) ServeHTTPe(w http.ResponseWriter, r *http.Request) error {
+	h := w.Header()
+	h.Add("Vary", "Origin")
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	if !c.allowOrigin(origin) {
+		return fmt.Errorf("%w: origin %q not allowed", ErrForbidden, origin)
+	}
+	method := r.Header.Get("Access-Control-Request-Method")
+	if r.Method != http.MethodOptions || method == "" {
+		c.setOriginHeaders(h, origin)
+		if len(c.ExposedHeaders) > 0 {
+			h.Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+		}
+		return nil
+	}
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+	if !c.allowMethod(method) {
+		return fmt.Errorf("%w: method %s not allowed", ErrForbidden, method)
+	}
+	headers := r.Header.Get("Access-Control-Request-Headers")
+	if name, ok := c.allowHeaders(headers); !ok {
+		return fmt.Errorf("%w: header %s not allowed", ErrForbidden, name)
+	}
+	c.setOriginHeaders(h, origin)
+	h.Set("Access-Control-Allow-Methods", method)
+	if headers != "" {
+		h.Set("Access-Control-Allow-Headers", headers)
+	}
+	if c.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.FormatInt(ceilSeconds(c.MaxAge), 10))
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return ErrHandled
+}
+
+func (c CORS) setOriginHeaders(h http.Header, origin string) {
+	if c.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	} else if slices.Contains(c.AllowedOrigins, "*") {
+		origin = "*"
+	}
+	h.Set("Access-Control-Allow-Origin", origin)
+}
+
+func (c CORS) allowOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" && c.AllowCredentials {
+			continue
+		}
+		prefix, suffix, wildcard := strings.Cut(allowed, "*")
+		if !wildcard && strings.EqualFold(origin, allowed) {
+			return true
+		}
+		if wildcard && len(origin) > len(prefix)+len(suffix) &&
+			strings.EqualFold(origin[:len(prefix)], prefix) &&
+			strings.EqualFold(origin[len(origin)-len(suffix):], suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c CORS) allowMethod(method string) bool {
+	methods := c.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	return slices.Contains(methods, method)
+}
+
+// allowHeaders returns the first of the comma-separated header names that
+// is not allowed and false, or true if all are allowed.
+func (c CORS) allowHeaders(headers string) (string, bool) {
+	if slices.Contains(c.AllowedHeaders, "*") {
+		return "", true
+	}
+	for _, name := range strings.Split(headers, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && !slices.ContainsFunc(c.AllowedHeaders, func(h string) bool { return strings.EqualFold(h, name) }) {
+			return name, false
+		}
+	}
+	return "", true
+}
@@ -0,0 +1,137 @@
+package httpe
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORS(t *testing.T) {
+	c := CORS{
+		AllowedOrigins: []string{"https://example.com", "https://*.example.org"},
+		ExposedHeaders: []string{"ETag", "Link"},
+	}
+	tests := map[string]string{
+		"https://example.com":     "https://example.com",
+		"https://EXAMPLE.com":     "https://EXAMPLE.com",
+		"https://app.example.org": "https://app.example.org",
+	}
+	for origin, want := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		require.NoError(t, c.ServeHTTPe(w, r), origin)
+		require.Equal(t, want, w.Header().Get("Access-Control-Allow-Origin"), origin)
+		require.Equal(t, "ETag, Link", w.Header().Get("Access-Control-Expose-Headers"), origin)
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"), origin)
+		require.Equal(t, []string{"Origin"}, w.Header().Values("Vary"), origin)
+	}
+
+	for _, origin := range []string{"https://example.net", "https://example.org", "http://app.example.org"} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Origin", origin)
+		err := c.ServeHTTPe(httptest.NewRecorder(), r)
+		require.True(t, errors.Is(err, ErrForbidden), origin)
+	}
+
+	w := httptest.NewRecorder()
+	require.NoError(t, c.ServeHTTPe(w, httptest.NewRequest("GET", "/", nil)))
+	require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "Origin", w.Header().Get("Vary"))
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	r := httptest.NewRequest("POST", "/", nil)
+	r.Header.Set("Origin", "https://example.com")
+
+	w := httptest.NewRecorder()
+	require.NoError(t, CORS{AllowedOrigins: []string{"*"}}.ServeHTTPe(w, r))
+	require.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	require.Empty(t, w.Header().Get("Access-Control-Expose-Headers"))
+
+	w = httptest.NewRecorder()
+	c := CORS{AllowedOrigins: []string{"*"}, AllowCredentials: true}
+	err := c.ServeHTTPe(w, r)
+	require.True(t, errors.Is(err, ErrForbidden), err)
+	require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	require.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	w = httptest.NewRecorder()
+	c = CORS{AllowedOrigins: []string{"*", "https://example.com"}, AllowCredentials: true}
+	require.NoError(t, c.ServeHTTPe(w, r))
+	require.Equal(t, "https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORSPreflight(t *testing.T) {
+	c := &CORS{
+		AllowedOrigins: []string{"https://example.com"},
+		AllowedMethods: []string{"GET", "PUT"},
+		AllowedHeaders: []string{"Content-Type", "X-Request-Id"},
+		MaxAge:         90*time.Second + time.Millisecond,
+	}
+	var called bool
+	h := Must(c, Put, func(http.ResponseWriter, *http.Request) { called = true })
+
+	r := httptest.NewRequest("OPTIONS", "/", nil)
+	r.Header.Set("Origin", "https://example.com")
+	r.Header.Set("Access-Control-Request-Method", "PUT")
+	r.Header.Set("Access-Control-Request-Headers", "content-type,x-request-id")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.False(t, called)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	want := http.Header{
+		"Vary":                         {"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		"Access-Control-Allow-Origin":  {"https://example.com"},
+		"Access-Control-Allow-Methods": {"PUT"},
+		"Access-Control-Allow-Headers": {"content-type,x-request-id"},
+		"Access-Control-Max-Age":       {"91"},
+	}
+	require.Equal(t, want, w.Header())
+
+	r.Header.Set("Access-Control-Request-Headers", "x-request-id, authorization")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	r.Header.Del("Access-Control-Request-Headers")
+	r.Header.Set("Access-Control-Request-Method", "DELETE")
+	err := c.ServeHTTPe(httptest.NewRecorder(), r)
+	require.True(t, errors.Is(err, ErrForbidden))
+	require.Equal(t, "Forbidden: method DELETE not allowed", err.Error())
+
+	r.Header.Set("Access-Control-Request-Method", "PUT")
+	w = httptest.NewRecorder()
+	require.Equal(t, ErrHandled, c.ServeHTTPe(w, r))
+	require.Empty(t, w.Header().Get("Access-Control-Allow-Headers"))
+
+	r = httptest.NewRequest("PUT", "/", nil)
+	r.Header.Set("Origin", "https://example.com")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.True(t, called)
+	require.Equal(t, "https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSPreflightDefaults(t *testing.T) {
+	c := CORS{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"*"}}
+	r := httptest.NewRequest("OPTIONS", "/", nil)
+	r.Header.Set("Origin", "https://example.com")
+	r.Header.Set("Access-Control-Request-Method", "POST")
+	r.Header.Set("Access-Control-Request-Headers", "x-anything")
+	w := httptest.NewRecorder()
+	require.Equal(t, ErrHandled, c.ServeHTTPe(w, r))
+	require.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "x-anything", w.Header().Get("Access-Control-Allow-Headers"))
+	require.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+
+	r.Header.Set("Access-Control-Request-Method", "PATCH")
+	err := c.ServeHTTPe(httptest.NewRecorder(), r)
+	require.True(t, errors.Is(err, ErrForbidden))
+}
@@ -3,15 +3,18 @@ package httpe
 import (
 	"errors"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 // StatusError wraps an http.StatusCode of value 4xx or 5xx. It is used
 // in combination with various sentinel values each representing a http
-// status code for convenience with HandlerE and HandlerFuncE.
+// status code for convenience with HandlerE and HandlerFuncE. A 3xx
+// StatusError is wrapped by a Redirect.
 type StatusError int
 
 // Error returns the error message and implements the error interface.
-func (err StatusError) Error() string { return http.StatusText(int(err)) }
+func (err StatusError) Error() string { return statusText(int(err)) }
 
 // IsClientError returns true if the error is in range 400 to 499.
 func (err StatusError) IsClientError() bool { return int(err) >= 400 && int(err) <= 499 }
@@ -65,6 +68,30 @@ var (
 	ErrNetworkAuthenticationRequired = StatusError(http.StatusNetworkAuthenticationRequired)
 )
 
+// StatusClientClosedRequest is the non-standard status code used by nginx
+// when the client closes the connection before the response is written.
+const StatusClientClosedRequest = 499
+
+// ErrClientClosedRequest is the StatusError for StatusClientClosedRequest.
+var ErrClientClosedRequest = StatusError(StatusClientClosedRequest)
+
+// statusText returns the text for the HTTP status code, including for
+// StatusClientClosedRequest.
+func statusText(code int) string {
+	if code == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(code)
+}
+
+// StatusCode returns the Code of the StatusError wrapped by err, or 500 if
+// err does not wrap a StatusError.
+func StatusCode(err error) int {
+	sErr := ErrInternalServerError
+	errors.As(err, &sErr)
+	return sErr.Code()
+}
+
 // WriteSafeErr writes err as an HTTP error to the http.ResponseWriter.
 //
 // If err wraps a StatusError, WriteSafeErr writes the Code of that error as
@@ -78,8 +105,17 @@ var (
 // ErrInternalServerError. This prevents leaking internal details from a server
 // error into the response to the client, but allows adding information to a
 // client error to inform the client of details of that error.
+//
+// The response headers carried by err are set with SetErrHeaders. Nothing
+// is written if the response has already been committed (see Committed).
+// For a redirect, such as a Redirect, only the status code and headers are
+// written.
 func WriteSafeErr(w http.ResponseWriter, err error) {
-	if err == nil {
+	if err == nil || Committed(w) {
+		return
+	}
+	SetErrHeaders(w.Header(), err)
+	if writeRedirect(w, err) {
 		return
 	}
 	sErr := ErrInternalServerError
@@ -89,3 +125,104 @@ func WriteSafeErr(w http.ResponseWriter, err error) {
 	}
 	http.Error(w, err.Error(), sErr.Code())
 }
+
+// writeRedirect writes the status code of err without a body and returns
+// true if err wraps a StatusError with a 3xx code. Otherwise it returns
+// false.
+func writeRedirect(w http.ResponseWriter, err error) bool {
+	code := StatusCode(err)
+	if code < 300 || code > 399 {
+		return false
+	}
+	w.WriteHeader(code)
+	return true
+}
+
+// HeaderCarrier is implemented by errors that carry HTTP response headers
+// to be written with the error response, such as the Allow header of a 405
+// response.
+type HeaderCarrier interface {
+	ResponseHeader() http.Header
+}
+
+// HeaderError is a StatusError with HTTP response headers to be written
+// with it. It wraps the StatusError so that errors.Is and errors.As can be
+// used on it as with the StatusError alone.
+type HeaderError struct {
+	StatusError
+	Header http.Header
+}
+
+// Unwrap returns the StatusError of err.
+func (err *HeaderError) Unwrap() error { return err.StatusError }
+
+// ResponseHeader returns the headers of err and implements HeaderCarrier.
+func (err *HeaderError) ResponseHeader() http.Header { return err.Header }
+
+// Unauthorized returns a HeaderError for ErrUnauthorized with the given
+// challenges as WWW-Authenticate headers, for example
+// Unauthorized(`Basic realm="api"`).
+func Unauthorized(challenges ...string) *HeaderError {
+	return &HeaderError{
+		StatusError: ErrUnauthorized,
+		Header:      http.Header{"Www-Authenticate": challenges},
+	}
+}
+
+// RetryAfter returns a HeaderError for the given StatusError, typically
+// ErrTooManyRequests or ErrServiceUnavailable, with a Retry-After header of
+// d rounded up to whole seconds.
+func RetryAfter(se StatusError, d time.Duration) *HeaderError {
+	if d < 0 {
+		d = 0
+	}
+	return &HeaderError{
+		StatusError: se,
+		Header:      http.Header{"Retry-After": {strconv.FormatInt(ceilSeconds(d), 10)}},
+	}
+}
+
+// ceilSeconds returns the non-negative duration d in seconds, rounded up.
+func ceilSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
+
+// SetErrHeaders sets the response headers carried by the errors in the tree
+// of err that implement HeaderCarrier, replacing any existing values for
+// those headers in h. If more than one error carries the same header, the
+// outermost wins. ErrWriters call SetErrHeaders before writing the status.
+func SetErrHeaders(h http.Header, err error) {
+	seen := map[string]bool{}
+	walkErr(err, func(e error) {
+		hc, ok := e.(HeaderCarrier)
+		if !ok {
+			return
+		}
+		for key, vals := range hc.ResponseHeader() {
+			key = http.CanonicalHeaderKey(key)
+			if !seen[key] {
+				seen[key] = true
+				h[key] = append([]string(nil), vals...)
+			}
+		}
+	})
+}
+
+// walkErr calls f for err and each error in its tree of wrapped errors,
+// in a pre-order, depth-first traversal.
+func walkErr(err error, f func(error)) {
+	for err != nil {
+		f(err)
+		switch u := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				walkErr(e, f)
+			}
+			return
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		default:
+			return
+		}
+	}
+}
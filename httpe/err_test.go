@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"foxygo.at/s/mock"
 	"github.com/stretchr/testify/require"
@@ -70,3 +71,80 @@ func TestNoLeak(t *testing.T) {
 	WriteSafeErr(w, err)
 	require.Contains(t, w.LastBody, "secret")
 }
+
+func TestWriteSafeErrAllow(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := &MethodNotAllowedError{Allow: []string{http.MethodGet, http.MethodHead}}
+	WriteSafeErr(w, err)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	require.Equal(t, "GET, HEAD", w.Header().Get("Allow"))
+}
+
+func TestHeaderError(t *testing.T) {
+	err := fmt.Errorf("%w: token expired", Unauthorized(`Bearer realm="api"`, `Basic realm="api"`))
+	require.True(t, errors.Is(err, ErrUnauthorized))
+	var sErr StatusError
+	require.True(t, errors.As(err, &sErr))
+	require.Equal(t, http.StatusUnauthorized, sErr.Code())
+	require.Equal(t, "Unauthorized: token expired", err.Error())
+
+	w := httptest.NewRecorder()
+	WriteSafeErr(w, err)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Equal(t, []string{`Bearer realm="api"`, `Basic realm="api"`}, w.Header().Values("WWW-Authenticate"))
+	require.Equal(t, "Unauthorized: token expired\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	WriteSafeErr(w, fmt.Errorf("%w: secret", RetryAfter(ErrServiceUnavailable, 1500*time.Millisecond)))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, "2", w.Header().Get("Retry-After"))
+	require.NotContains(t, w.Body.String(), "secret")
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := map[time.Duration]string{
+		-time.Second:     "0",
+		0:                "0",
+		time.Millisecond: "1",
+		time.Second:      "1",
+		time.Minute:      "60",
+	}
+	for d, want := range tests {
+		err := RetryAfter(ErrTooManyRequests, d)
+		require.Equal(t, want, err.ResponseHeader().Get("Retry-After"))
+		require.True(t, errors.Is(err, ErrTooManyRequests))
+	}
+}
+
+type headerErr http.Header
+
+func (e headerErr) Error() string { return "header error" }
+
+func (e headerErr) ResponseHeader() http.Header { return http.Header(e) }
+
+func TestSetErrHeaders(t *testing.T) {
+	inner := headerErr{"X-A": {"inner"}, "X-B": {"inner"}}
+	outer := headerErr{"x-a": {"outer"}}
+	sibling := headerErr{"X-B": {"sibling"}, "X-C": {"sibling1", "sibling2"}}
+	err := fmt.Errorf("%w", joinErr{joinErr{outer, inner}, sibling})
+
+	h := http.Header{"X-A": {"original"}, "X-D": {"original"}}
+	SetErrHeaders(h, err)
+	want := http.Header{
+		"X-A": {"outer"},
+		"X-B": {"inner"},
+		"X-C": {"sibling1", "sibling2"},
+		"X-D": {"original"},
+	}
+	require.Equal(t, want, h)
+
+	h = http.Header{}
+	SetErrHeaders(h, errors.New("no headers"))
+	require.Empty(t, h)
+}
+
+func TestStatusCode(t *testing.T) {
+	require.Equal(t, http.StatusNotFound, StatusCode(ErrNotFound))
+	require.Equal(t, http.StatusUnauthorized, StatusCode(fmt.Errorf("%w", Unauthorized())))
+	require.Equal(t, http.StatusInternalServerError, StatusCode(errors.New("💥")))
+}
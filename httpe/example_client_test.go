@@ -0,0 +1,28 @@
+package httpe_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"foxygo.at/s/httpe"
+)
+
+func ExampleClient() {
+	server := httptest.NewServer(httpe.Must(func(http.ResponseWriter, *http.Request) error {
+		return fmt.Errorf("%w: no such user", httpe.ErrNotFound)
+	}, httpe.WriteProblem))
+	defer server.Close()
+
+	client := httpe.NewClient(nil)
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", server.URL+"/users/42", nil)
+	var user struct{ Name string }
+	err := client.DoJSON(req, &user)
+	fmt.Println(errors.Is(err, httpe.ErrNotFound))
+	fmt.Println(err)
+	// output:
+	// true
+	// Not Found: no such user
+}
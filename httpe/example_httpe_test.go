@@ -64,3 +64,13 @@ func writeErr(w http.ResponseWriter, err error) {
 		http.Error(w, "something went wrong", http.StatusInternalServerError)
 	}
 }
+
+func ExampleWriteProblem() {
+	handler := httpe.NewHandlerFunc(handle, httpe.WithErrWriterFunc(httpe.WriteProblem))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/user", strings.NewReader(`{"Name": "truncated...`))
+	handler(w, r)
+	fmt.Printf("%d %s %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	// output: 500 application/problem+json {"status":500,"title":"Internal Server Error"}
+}
@@ -0,0 +1,46 @@
+package httpe_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+
+	"foxygo.at/s/httpe"
+)
+
+type sumRequest struct {
+	Numbers []int `json:"numbers"`
+}
+
+type sumResponse struct {
+	Sum int `json:"sum"`
+}
+
+func sum(_ context.Context, req sumRequest) (sumResponse, error) {
+	if len(req.Numbers) == 0 {
+		return sumResponse{}, fmt.Errorf("%w: no numbers", httpe.ErrBadRequest)
+	}
+	resp := sumResponse{}
+	for _, n := range req.Numbers {
+		resp.Sum += n
+	}
+	return resp, nil
+}
+
+func ExampleJSON() {
+	handler := httpe.Must(httpe.Post, httpe.JSON(sum))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/sum", strings.NewReader(`{"numbers": [1, 2, 3]}`))
+	handler.ServeHTTP(w, r)
+	fmt.Printf("%d %s", w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/sum", strings.NewReader(`{"numbers": "one"}`))
+	handler.ServeHTTP(w, r)
+	fmt.Printf("%d %s", w.Code, w.Body.String())
+	// output:
+	// 200 {"sum":6}
+	// 400 Bad Request: field "numbers": cannot use JSON string as []int
+}
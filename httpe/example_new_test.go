@@ -64,3 +64,17 @@ func errWriter(w http.ResponseWriter, err error) {
 	}
 	fmt.Fprintf(w, "🐈 "+strings.ToUpper(err.Error())+"!!!1!")
 }
+
+func ExampleMethods() {
+	get := httpe.HandlerFuncE(func(w http.ResponseWriter, _ *http.Request) error {
+		fmt.Fprintf(w, "got it")
+		return nil
+	})
+	handler := httpe.Must(httpe.Methods{http.MethodGet: get})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/hello", nil)
+	handler.ServeHTTP(w, r)
+	fmt.Printf("%d %s %s", w.Code, w.Header().Get("Allow"), w.Body.String())
+	// output: 405 GET, HEAD, OPTIONS Method Not Allowed
+}
@@ -0,0 +1,31 @@
+package httpe_test
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"foxygo.at/s/httpe"
+)
+
+func ExampleSlogErrObserver() {
+	// Remove the time from log output for a reproducible example.
+	removeTime := func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return a
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: removeTime}))
+
+	handler := httpe.Must(func(http.ResponseWriter, *http.Request) error {
+		return errors.New("database on fire")
+	}, httpe.SlogErrObserver(logger))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/users", nil)
+	handler.ServeHTTP(w, r)
+	// output: level=ERROR msg="http error" method=GET path=/users status=500 error="database on fire"
+}
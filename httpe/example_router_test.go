@@ -0,0 +1,32 @@
+package httpe_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"foxygo.at/s/httpe"
+)
+
+type user struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func getUser(_ context.Context, _ struct{}) (user, error) {
+	return user{}, httpe.ErrNotFound
+}
+
+func ExampleRouter() {
+	rt := httpe.NewRouter()
+	rt.Handle("GET /users/{id}", httpe.AcceptJSON, httpe.JSON(getUser)).Declare(httpe.ErrNotFound)
+	rt.Handle("/sum", httpe.Post, httpe.JSON(sum))
+	rt.Handle("/health", func(w http.ResponseWriter, _ *http.Request) {})
+
+	_ = rt.WriteRoutes(os.Stdout)
+	// output:
+	// METHOD  PATH         REQUEST                RESPONSE                GUARDS                    ERRORS
+	// GET     /users/{id}  struct {}              httpe_test.user         Accept(application/json)  400, 404, 405, 415
+	// POST    /sum         httpe_test.sumRequest  httpe_test.sumResponse  -                         400, 405, 415
+	// *       /health      -                      -                       -                         -
+}
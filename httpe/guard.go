@@ -0,0 +1,157 @@
+package httpe
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ContentTypeJSON is a HandlerE that returns ErrUnsupportedMediaType
+	// if the request has a body that is not application/json. Use with
+	// Chain or New/Must.
+	ContentTypeJSON = ContentType("application/json")
+
+	// AcceptJSON is a HandlerE that returns ErrNotAcceptable if the
+	// request does not accept application/json. Use with Chain or
+	// New/Must.
+	AcceptJSON = Accept("application/json")
+)
+
+// Guard is a HandlerE with a name describing it, such as the guards
+// returned by MaxBytes or BasicAuth. A Router records the name of a guard
+// in the Guards of a Route. Any HandlerE can be named by using it as the
+// Handler of a Guard, for example Guard{Name: "admin only", Handler: h}.
+type Guard struct {
+	Name    string
+	Handler HandlerE
+}
+
+// ServeHTTPe calls the Handler of g and returns its error.
+func (g Guard) ServeHTTPe(w http.ResponseWriter, r *http.Request) error {
+	return g.Handler.ServeHTTPe(w, r)
+}
+
+// ServeHTTPr calls the Handler of g and returns the request it derives
+// and its error, so that a Guard can be used like its Handler in a Chain.
+func (g Guard) ServeHTTPr(w http.ResponseWriter, r *http.Request) (*http.Request, error) {
+	return serve(g.Handler, w, r)
+}
+
+// String returns the name of g and implements fmt.Stringer.
+func (g Guard) String() string { return g.Name }
+
+// MaxBytes returns a HandlerE that limits the request body to n bytes. Use
+// with Chain or New/Must.
+//
+// A request with a Content-Length greater than n results in
+// ErrRequestEntityTooLarge. Otherwise the request body is replaced with an
+// http.MaxBytesReader, so that reading more than n bytes in a later handler
+// fails with an *http.MaxBytesError, which DefaultClassifier classifies as
+// ErrRequestEntityTooLarge.
+func MaxBytes(n int64) Guard {
+	f := func(w http.ResponseWriter, r *http.Request) error {
+		if r.ContentLength > n {
+			return fmt.Errorf("%w: body exceeds %d bytes", ErrRequestEntityTooLarge, n)
+		}
+		if r.Body != nil {
+			// The original ResponseWriter lets net/http close the
+			// connection after an oversized body.
+			r.Body = http.MaxBytesReader(originalResponseWriter(w), r.Body, n)
+		}
+		return nil
+	}
+	return Guard{Name: fmt.Sprintf("MaxBytes(%d)", n), Handler: HandlerFuncE(f)}
+}
+
+// ContentType returns a HandlerE that returns ErrUnsupportedMediaType if
+// the request has a body and its Content-Type is not one of the given
+// media types. A body without a Content-Type is taken to be
+// application/octet-stream. A media type may be a wildcard such as
+// "text/*". The error carries the media types as the Accept header of the
+// response. Use with Chain or New/Must.
+func ContentType(mediaTypes ...string) Guard {
+	accept := strings.Join(mediaTypes, ", ")
+	f := func(_ http.ResponseWriter, r *http.Request) error {
+		ct := r.Header.Get("Content-Type")
+		if ct == "" {
+			if !hasBody(r) {
+				return nil
+			}
+			ct = "application/octet-stream"
+		}
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err == nil && matchMediaType(mediaType, mediaTypes) {
+			return nil
+		}
+		hErr := &HeaderError{
+			StatusError: ErrUnsupportedMediaType,
+			Header:      http.Header{"Accept": {accept}},
+		}
+		return fmt.Errorf("%w: %q", hErr, ct)
+	}
+	return Guard{Name: "ContentType(" + accept + ")", Handler: HandlerFuncE(f)}
+}
+
+// Accept returns a HandlerE that returns ErrNotAcceptable if the Accept
+// header of the request accepts none of the given media types. A request
+// without an Accept header accepts any media type. Use with Chain or
+// New/Must.
+func Accept(mediaTypes ...string) Guard {
+	f := func(_ http.ResponseWriter, r *http.Request) error {
+		if negotiate(r.Header.Get("Accept"), mediaTypes) == "" {
+			return fmt.Errorf("%w: expected one of %s", ErrNotAcceptable, strings.Join(mediaTypes, ", "))
+		}
+		return nil
+	}
+	return Guard{Name: "Accept(" + strings.Join(mediaTypes, ", ") + ")", Handler: HandlerFuncE(f)}
+}
+
+// conditionalHeaders are the request headers that make a request
+// conditional, as described in RFC 9110 section 13.1.
+var conditionalHeaders = map[string]bool{
+	"If-Match":            true,
+	"If-None-Match":       true,
+	"If-Modified-Since":   true,
+	"If-Unmodified-Since": true,
+	"If-Range":            true,
+}
+
+// RequireHeader returns a HandlerE that returns an error if any of the
+// named headers is missing from the request. The error is
+// ErrPreconditionRequired if the missing header is a conditional header,
+// such as If-Match, and ErrBadRequest otherwise. Use with Chain or
+// New/Must.
+func RequireHeader(names ...string) Guard {
+	f := func(_ http.ResponseWriter, r *http.Request) error {
+		for _, name := range names {
+			if _, ok := r.Header[http.CanonicalHeaderKey(name)]; ok {
+				continue
+			}
+			if conditionalHeaders[http.CanonicalHeaderKey(name)] {
+				return fmt.Errorf("%w: missing %s header", ErrPreconditionRequired, name)
+			}
+			return fmt.Errorf("%w: missing %s header", ErrBadRequest, name)
+		}
+		return nil
+	}
+	return Guard{Name: "RequireHeader(" + strings.Join(names, ", ") + ")", Handler: HandlerFuncE(f)}
+}
+
+// hasBody returns true if r may have a non-empty body.
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && (r.ContentLength != 0 || len(r.TransferEncoding) > 0)
+}
+
+// matchMediaType returns true if mediaType matches any of patterns, which
+// may be wildcards such as "text/*" or "*/*".
+func matchMediaType(mediaType string, patterns []string) bool {
+	typ, _, _ := strings.Cut(mediaType, "/")
+	for _, p := range patterns {
+		if p == mediaType || p == typ+"/*" || p == "*/*" {
+			return true
+		}
+	}
+	return false
+}
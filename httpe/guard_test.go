@@ -0,0 +1,166 @@
+package httpe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxBytes(t *testing.T) {
+	h := MaxBytes(4)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader("hello"))
+	err := h.ServeHTTPe(w, r)
+	require.True(t, errors.Is(err, ErrRequestEntityTooLarge))
+	require.Equal(t, "Request Entity Too Large: body exceeds 4 bytes", err.Error())
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader("hello"))
+	r.ContentLength = -1
+	require.NoError(t, h.ServeHTTPe(w, r))
+	_, err = io.ReadAll(r.Body)
+	var maxBytesErr *http.MaxBytesError
+	require.True(t, errors.As(err, &maxBytesErr))
+	require.True(t, errors.Is(DefaultClassifier.Classify(err), ErrRequestEntityTooLarge))
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader("hi"))
+	require.NoError(t, h.ServeHTTPe(w, r))
+	b, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	require.Equal(t, "hi", string(b))
+
+	require.NoError(t, h.ServeHTTPe(w, &http.Request{}))
+}
+
+func TestMaxBytesCloseConnection(t *testing.T) {
+	read := func(_ http.ResponseWriter, r *http.Request) error {
+		_, err := io.ReadAll(r.Body)
+		return err
+	}
+	srv := httptest.NewServer(Must(MaxBytes(4), read))
+	defer srv.Close()
+	r, err := http.NewRequest("POST", srv.URL, io.MultiReader(strings.NewReader("hello")))
+	require.NoError(t, err)
+	resp, err := srv.Client().Do(r)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	require.True(t, resp.Close)
+}
+
+func TestOriginalResponseWriter(t *testing.T) {
+	w := httptest.NewRecorder()
+	require.Equal(t, w, originalResponseWriter(wrapResponseWriter(wrapResponseWriter(w))))
+}
+
+func TestContentType(t *testing.T) {
+	h := ContentType("application/json", "text/*")
+	tests := map[string]bool{
+		"application/json":                true,
+		"application/json; charset=utf-8": true,
+		"text/csv":                        true,
+		"application/xml":                 false,
+		"application/json;;":              false,
+		"":                                false,
+	}
+	for ct, ok := range tests {
+		r := httptest.NewRequest("POST", "/", strings.NewReader("{}"))
+		if ct != "" {
+			r.Header.Set("Content-Type", ct)
+		}
+		err := h.ServeHTTPe(httptest.NewRecorder(), r)
+		if ok {
+			require.NoError(t, err, ct)
+			continue
+		}
+		require.True(t, errors.Is(err, ErrUnsupportedMediaType), ct)
+		hdr := http.Header{}
+		SetErrHeaders(hdr, err)
+		require.Equal(t, "application/json, text/*", hdr.Get("Accept"))
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	require.NoError(t, h.ServeHTTPe(httptest.NewRecorder(), r))
+	require.NoError(t, ContentTypeJSON.ServeHTTPe(httptest.NewRecorder(), r))
+	require.NoError(t, ContentType("*/*").ServeHTTPe(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader("x"))))
+}
+
+func TestAccept(t *testing.T) {
+	tests := map[string]bool{
+		"":                                true,
+		"*/*":                             true,
+		"application/*":                   true,
+		"application/json":                true,
+		"text/html":                       false,
+		"application/json;q=0, text/html": false,
+	}
+	for accept, ok := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", accept)
+		err := AcceptJSON.ServeHTTPe(httptest.NewRecorder(), r)
+		if ok {
+			require.NoError(t, err, accept)
+		} else {
+			require.True(t, errors.Is(err, ErrNotAcceptable), accept)
+			require.Equal(t, "Not Acceptable: expected one of application/json", err.Error())
+		}
+	}
+}
+
+func TestRequireHeader(t *testing.T) {
+	h := RequireHeader("x-api-version", "If-Match")
+	r := httptest.NewRequest("PUT", "/", nil)
+	err := h.ServeHTTPe(httptest.NewRecorder(), r)
+	require.True(t, errors.Is(err, ErrBadRequest))
+	require.Equal(t, "Bad Request: missing x-api-version header", err.Error())
+
+	r.Header.Set("X-Api-Version", "2")
+	err = h.ServeHTTPe(httptest.NewRecorder(), r)
+	require.True(t, errors.Is(err, ErrPreconditionRequired))
+
+	r.Header.Set("If-Match", `"v1"`)
+	require.NoError(t, h.ServeHTTPe(httptest.NewRecorder(), r))
+}
+
+func TestGuardNames(t *testing.T) {
+	always := func(*http.Request, user) bool { return true }
+	verifyUser := func(context.Context, string, string) (user, bool, error) { return user{}, true, nil }
+	validators := func(*http.Request) (Validators, error) { return Validators{}, nil }
+	tests := map[string]fmt.Stringer{
+		"MaxBytes(1024)":                MaxBytes(1024),
+		"ContentType(application/json)": ContentTypeJSON,
+		"Accept(text/html, text/plain)": Accept("text/html", "text/plain"),
+		"RequireHeader(If-Match)":       RequireHeader("If-Match"),
+		"BasicAuth(api)":                BasicAuth("api", verifyUser),
+		"BearerAuth(api)":               BearerAuth("api", verifySecret),
+		"APIKeyAuth(X-API-Key)":         APIKeyAuth("X-API-Key", verifySecret),
+		"Authorize":                     Authorize(always),
+		"RateLimit(10/1m0s)":            RateLimit(Limit{Requests: 10, Period: time.Minute}, ClientIP, NewMemoryLimitStore()),
+		"Conditional":                   Conditional(validators),
+		"ConditionalRequired":           ConditionalRequired(validators),
+		"GET":                           Get,
+	}
+	for want, g := range tests {
+		require.Equal(t, want, g.String())
+	}
+}
+
+func TestGuardDerivedRequest(t *testing.T) {
+	key := NewContextKey[string]("key")
+	derive := func(_ http.ResponseWriter, r *http.Request) (*http.Request, error) {
+		return key.WithValue(r, "value"), nil
+	}
+	g := Guard{Name: "derive", Handler: RequestHandlerFuncE(derive)}
+	r, err := g.ServeHTTPr(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	v, _ := key.Value(r.Context())
+	require.Equal(t, "value", v)
+	require.NoError(t, g.ServeHTTPe(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)))
+}
@@ -0,0 +1,142 @@
+package httpe
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+)
+
+var defaultHTMLErrTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{.Status}} {{.Title}}</title></head>
+<body>
+<h1>{{.Status}} {{.Title}}</h1>
+<p>{{.Message}}</p>
+{{- if .Errors}}
+<h2>Errors</h2>
+<ol>
+{{- range .Errors}}
+<li>{{.}}</li>
+{{- end}}
+</ol>
+{{- end}}
+{{- if .Stack}}
+<h2>Stack</h2>
+<pre>{{.Stack}}</pre>
+{{- end}}
+</body>
+</html>
+`))
+
+// HTMLErrData is the data an HTMLErrWriter executes its template with.
+type HTMLErrData struct {
+	// Status is the status code of the response.
+	Status int
+	// Title is the text for the status code.
+	Title string
+	// Message is the error string for client errors and the Title
+	// otherwise, following the same rules as WriteSafeErr.
+	Message string
+	// Problem is the problem details document for the error as returned
+	// by NewProblem.
+	Problem *Problem
+	// Request is the request the error occurred in, if known.
+	Request *http.Request
+	// Errors are the error strings of all errors in the tree of the error,
+	// outermost first. They are only set for server errors in development
+	// mode.
+	Errors []string
+	// Stack is the stack trace of a *PanicError in the tree of the error.
+	// It is only set for server errors in development mode.
+	Stack string
+}
+
+// HTMLErrWriter is a RequestErrWriter that writes errors as HTML pages
+// rendered with an html/template.
+//
+// If Template has an associated template named after the status code of
+// the error, such as "404", or its class, such as "4xx", that template is
+// executed, otherwise Template itself is. If Template is nil or fails to
+// execute, a built-in default page is written. Templates are executed with
+// an *HTMLErrData.
+//
+// If Dev is true, the data for server errors includes the strings of all
+// errors in the tree of the error and the stack trace of a panic. Dev must
+// not be enabled in production as it discloses internal details.
+//
+// For a redirect, such as a Redirect, only the status code and headers are
+// written.
+type HTMLErrWriter struct {
+	Template *template.Template
+	Dev      bool
+}
+
+// WriteRequestErr writes err as an HTML page and implements
+// RequestErrWriter.
+func (hw *HTMLErrWriter) WriteRequestErr(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil || Committed(w) {
+		return
+	}
+	SetErrHeaders(w.Header(), err)
+	if writeRedirect(w, err) {
+		return
+	}
+	data := newHTMLErrData(r, err, hw.Dev)
+	b, tErr := executeTemplate(hw.lookup(data.Status), data)
+	if tErr != nil {
+		b, _ = executeTemplate(defaultHTMLErrTemplate, data)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(data.Status)
+	_, _ = w.Write(b)
+}
+
+// lookup returns the template for the status code.
+func (hw *HTMLErrWriter) lookup(status int) *template.Template {
+	if hw.Template == nil {
+		return defaultHTMLErrTemplate
+	}
+	for _, name := range []string{strconv.Itoa(status), strconv.Itoa(status/100) + "xx"} {
+		if t := hw.Template.Lookup(name); t != nil {
+			return t
+		}
+	}
+	return hw.Template
+}
+
+func executeTemplate(t *template.Template, data *HTMLErrData) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := t.Execute(buf, data)
+	return buf.Bytes(), err
+}
+
+func newHTMLErrData(r *http.Request, err error, dev bool) *HTMLErrData {
+	p := NewProblem(err)
+	data := &HTMLErrData{Status: p.Status, Title: p.Title, Message: p.Detail, Problem: p, Request: r}
+	if data.Message == "" {
+		data.Message = p.Title
+	}
+	if !dev || p.Status < 500 {
+		return data
+	}
+	walkErr(err, func(e error) {
+		data.Errors = append(data.Errors, e.Error())
+	})
+	var pErr *PanicError
+	if errors.As(err, &pErr) {
+		data.Stack = string(pErr.Stack)
+	}
+	return data
+}
+
+// WriteHTMLErr writes err as an HTTP error to the http.ResponseWriter with
+// a body of a simple HTML page. The page contains the error string for
+// client errors and only the text for the status code otherwise, following
+// the same rules as WriteSafeErr. It writes the built-in default page of
+// HTMLErrWriter.
+func WriteHTMLErr(w http.ResponseWriter, err error) {
+	(&HTMLErrWriter{}).WriteRequestErr(w, nil, err)
+}
@@ -0,0 +1,91 @@
+package httpe
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHTMLErr(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteHTMLErr(w, fmt.Errorf("%w: <script>", ErrBadRequest))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Contains(t, w.Body.String(), "<h1>400 Bad Request</h1>")
+	require.Contains(t, w.Body.String(), "<p>Bad Request: &lt;script&gt;</p>")
+
+	w = httptest.NewRecorder()
+	WriteHTMLErr(w, errors.New("secret"))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.NotContains(t, w.Body.String(), "secret")
+	require.Contains(t, w.Body.String(), "<p>Internal Server Error</p>")
+
+	w = httptest.NewRecorder()
+	WriteHTMLErr(w, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Body.String())
+}
+
+func TestHTMLErrWriter(t *testing.T) {
+	tmpl := template.Must(template.New("error").Parse(`{{.Status}} {{.Message}}`))
+	template.Must(tmpl.New("404").Parse(`not found: {{.Request.URL.Path}}`))
+	template.Must(tmpl.New("5xx").Parse(`server error {{.Status}} {{.Title}}`))
+	hw := &HTMLErrWriter{Template: tmpl}
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("%w: <script>", ErrBadRequest), "400 Bad Request: &lt;script&gt;"},
+		{ErrNotFound, "not found: /a&lt;b"},
+		{errors.New("secret"), "server error 500 Internal Server Error"},
+		{ErrBadGateway, "server error 502 Bad Gateway"},
+	}
+	for _, tc := range tests {
+		w := httptest.NewRecorder()
+		hw.WriteRequestErr(w, httptest.NewRequest("GET", "/a%3Cb", nil), tc.err)
+		require.Equal(t, StatusCode(tc.err), w.Code, tc.err)
+		require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"), tc.err)
+		require.Equal(t, tc.want, w.Body.String(), tc.err)
+	}
+
+	w := httptest.NewRecorder()
+	hw.WriteRequestErr(w, nil, ErrNotFound)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Contains(t, w.Body.String(), "<h1>404 Not Found</h1>")
+
+	w = httptest.NewRecorder()
+	Must(errHandler(Unauthorized(`Basic realm="admin"`)), hw).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, "401 Unauthorized", w.Body.String())
+	require.Equal(t, `Basic realm="admin"`, w.Header().Get("WWW-Authenticate"))
+
+	w = httptest.NewRecorder()
+	hw.WriteRequestErr(w, nil, nil)
+	require.Empty(t, w.Body.String())
+}
+
+func TestHTMLErrWriterDev(t *testing.T) {
+	hw := &HTMLErrWriter{Dev: true}
+	panicky := func(http.ResponseWriter, *http.Request) error { panic("<boom>") }
+	w := httptest.NewRecorder()
+	Must(panicky, hw, WithRecover()).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	body := w.Body.String()
+	require.Contains(t, body, "<p>Internal Server Error</p>")
+	require.Contains(t, body, "<li>Internal Server Error: panic: &lt;boom&gt;</li>\n<li>Internal Server Error</li>")
+	require.Contains(t, body, "<h2>Stack</h2>\n<pre>goroutine")
+
+	w = httptest.NewRecorder()
+	hw.WriteRequestErr(w, nil, fmt.Errorf("loading: %w", errors.New("secret")))
+	body = w.Body.String()
+	require.Contains(t, body, "<li>loading: secret</li>\n<li>secret</li>")
+	require.NotContains(t, body, "Stack")
+
+	w = httptest.NewRecorder()
+	hw.WriteRequestErr(w, nil, fmt.Errorf("%w: no such page", ErrNotFound))
+	require.NotContains(t, w.Body.String(), "<li>")
+}
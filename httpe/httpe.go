@@ -24,6 +24,7 @@
 package httpe
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 )
@@ -36,6 +37,12 @@ type HandlerE interface {
 	ServeHTTPe(http.ResponseWriter, *http.Request) error
 }
 
+// ErrHandled is returned by a HandlerE that has written a complete
+// response, such as a 304 Not Modified response, to stop a Chain without
+// the remaining handlers being called. NewHandler treats an error that
+// wraps ErrHandled as no error: it is neither written nor observed.
+var ErrHandled = errors.New("httpe: request handled")
+
 // The HandlerFuncE type is an adapter to allow the use of ordinary
 // functions as HandlerE. If f is a function with the appropriate
 // signature, HandlerFuncE(f) is a HandlerE that calls f.
@@ -46,6 +53,46 @@ func (f HandlerFuncE) ServeHTTPe(w http.ResponseWriter, r *http.Request) error {
 	return f(w, r)
 }
 
+// RequestHandlerE is implemented by a HandlerE that derives a new request,
+// such as one with a new context value or a rewritten URL, for the
+// handlers that follow it in a Chain. ServeHTTPr works like ServeHTTPe but
+// also returns the derived request, or nil to leave the request unchanged.
+type RequestHandlerE interface {
+	HandlerE
+	ServeHTTPr(http.ResponseWriter, *http.Request) (*http.Request, error)
+}
+
+// The RequestHandlerFuncE type is an adapter to allow the use of ordinary
+// functions as RequestHandlerE. If f is a function with the appropriate
+// signature, RequestHandlerFuncE(f) is a RequestHandlerE that calls f.
+type RequestHandlerFuncE func(http.ResponseWriter, *http.Request) (*http.Request, error)
+
+// ServeHTTPe calls f(w, r) and returns its error, discarding the derived
+// request.
+func (f RequestHandlerFuncE) ServeHTTPe(w http.ResponseWriter, r *http.Request) error {
+	_, err := f(w, r)
+	return err
+}
+
+// ServeHTTPr calls f(w, r) and returns the derived request and error.
+func (f RequestHandlerFuncE) ServeHTTPr(w http.ResponseWriter, r *http.Request) (*http.Request, error) {
+	return f(w, r)
+}
+
+// serve calls h with w and r, returning the request derived by h if it is
+// a RequestHandlerE, or r.
+func serve(h HandlerE, w http.ResponseWriter, r *http.Request) (*http.Request, error) {
+	rh, ok := h.(RequestHandlerE)
+	if !ok {
+		return r, h.ServeHTTPe(w, r)
+	}
+	r2, err := rh.ServeHTTPr(w, r)
+	if r2 == nil {
+		r2 = r
+	}
+	return r2, err
+}
+
 // ErrWriter translates an error into the appropriate http response
 // StatusCode and Body and writes it.
 type ErrWriter interface {
@@ -63,45 +110,98 @@ func (ew ErrWriterFunc) WriteErr(w http.ResponseWriter, err error) {
 	ew(w, err)
 }
 
+// RequestErrWriter translates an error into the appropriate http response
+// StatusCode and Body for a request and writes it. It is used in place of
+// an ErrWriter when the response depends on the request, such as when
+// negotiating the content type of the response.
+type RequestErrWriter interface {
+	WriteRequestErr(http.ResponseWriter, *http.Request, error)
+}
+
+// The RequestErrWriterFunc type is an adapter to allow the use of ordinary
+// functions as RequestErrWriter. If f is a function with the appropriate
+// signature, RequestErrWriterFunc(f) is a RequestErrWriter that calls f.
+type RequestErrWriterFunc func(http.ResponseWriter, *http.Request, error)
+
+// WriteRequestErr translates an error into the appropriate http response
+// StatusCode and Body for the request and writes it.
+func (ew RequestErrWriterFunc) WriteRequestErr(w http.ResponseWriter, r *http.Request, err error) {
+	ew(w, r, err)
+}
+
+// ErrObserver is notified of each error returned by a HandlerE after it has
+// been written by the ErrWriter. It is given the request, the error as
+// returned by the HandlerE and the status code of the response. It is
+// intended for logging and metrics, in particular for server errors whose
+// details are hidden from the client by the ErrWriter.
+type ErrObserver interface {
+	ObserveErr(*http.Request, error, int)
+}
+
+// The ErrObserverFunc type is an adapter to allow the use of ordinary
+// functions as ErrObserver. If f is a function with the appropriate
+// signature, ErrObserverFunc(f) is an ErrObserver that calls f.
+type ErrObserverFunc func(*http.Request, error, int)
+
+// ObserveErr calls f(r, err, status).
+func (f ErrObserverFunc) ObserveErr(r *http.Request, err error, status int) {
+	f(r, err, status)
+}
+
 // New returns an http.Handler that calls in sequence all the args that are a
 // type of handler, stopping if any return an error. If any of the args is an
 // ErrWriter or a function that has the signature of an ErrWriterFunc, it will
 // be called to handle the error if there was one.
 //
 // The types that are recognised as handlers in the arg list are any type that
-// implements HandlerE (including HandlerFuncE), a function that matches the
-// signature of a HandlerFuncE, an http.Handler, or a function that matches the
+// implements HandlerE (including HandlerFuncE and RequestHandlerFuncE), a
+// function that matches the signature of a HandlerFuncE or a
+// RequestHandlerFuncE, an http.Handler, or a function that matches the
 // signature of an http.HandlerFunc. Args of the latter two are adapted to
 // always return a nil error.
 //
+// A RequestErrWriter or a function that has the signature of a
+// RequestErrWriterFunc is recognised as an ErrWriter that is also passed
+// the request. Any number of ErrObservers or functions that have the
+// signature of an ErrObserverFunc may be passed. Options as returned by
+// the With* functions, such as WithRecover, are passed through to
+// NewHandler.
+//
 // If an argument does not match any of the preceding types or more than one
 // ErrWriter is passed, an error is returned.
 func New(args ...interface{}) (http.Handler, error) {
 	handlers := make([]HandlerE, 0, len(args))
 	opts := []option{}
+	ewOpts := []option{}
 
 	for i, arg := range args {
+		if h, ok := handlerArg(arg); ok {
+			handlers = append(handlers, h)
+			continue
+		}
 		switch v := arg.(type) {
-		case HandlerE:
-			handlers = append(handlers, v)
-		case func(http.ResponseWriter, *http.Request) error:
-			handlers = append(handlers, HandlerFuncE(v))
-		case http.Handler:
-			handlers = append(handlers, handlerAdapter(v))
-		case func(http.ResponseWriter, *http.Request):
-			handlers = append(handlers, handlerAdapter(http.HandlerFunc(v)))
+		case RequestErrWriter:
+			ewOpts = append(ewOpts, WithRequestErrWriter(v))
+		case func(http.ResponseWriter, *http.Request, error):
+			ewOpts = append(ewOpts, WithRequestErrWriterFunc(v))
 		case ErrWriter:
-			opts = append(opts, WithErrWriter(v))
+			ewOpts = append(ewOpts, WithErrWriter(v))
 		case func(http.ResponseWriter, error):
-			opts = append(opts, WithErrWriterFunc(v))
+			ewOpts = append(ewOpts, WithErrWriterFunc(v))
+		case ErrObserver:
+			opts = append(opts, WithErrObserver(v))
+		case func(*http.Request, error, int):
+			opts = append(opts, WithErrObserverFunc(v))
+		case option:
+			opts = append(opts, v)
 		default:
 			return nil, fmt.Errorf("arg %d: unknown arg type: %T", i, v)
 		}
-		if len(opts) > 1 {
+		if len(ewOpts) > 1 {
 			return nil, fmt.Errorf("arg %d: too many ErrWriters", i)
 		}
 	}
-	return NewHandler(Chain(handlers...), opts...), nil
+	return NewHandler(Chain(handlers...), append(ewOpts, opts...)...), nil
 }
 
 // Must passes all its args to New() and panics if New() returns an error. If
@@ -114,6 +214,24 @@ func Must(args ...interface{}) http.Handler {
 	return h
 }
 
+// handlerArg returns arg as a HandlerE and true if it is one of the types
+// that New recognises as a handler, or false otherwise.
+func handlerArg(arg interface{}) (HandlerE, bool) {
+	switch v := arg.(type) {
+	case HandlerE:
+		return v, true
+	case func(http.ResponseWriter, *http.Request) error:
+		return HandlerFuncE(v), true
+	case func(http.ResponseWriter, *http.Request) (*http.Request, error):
+		return RequestHandlerFuncE(v), true
+	case http.Handler:
+		return handlerAdapter(v), true
+	case func(http.ResponseWriter, *http.Request):
+		return handlerAdapter(http.HandlerFunc(v)), true
+	}
+	return nil, false
+}
+
 // handlerAdapter turns an http.Handler into a HandlerE that returns nil.
 func handlerAdapter(h http.Handler) HandlerE {
 	f := func(w http.ResponseWriter, r *http.Request) error {
@@ -126,12 +244,45 @@ func handlerAdapter(h http.Handler) HandlerE {
 // NewHandler returns an http.Handler that calls h.ServeHTTPe and handles the
 // error returned, if any, with an ErrWriter to write the error to the
 // ResponseWriter. The default ErrWriter is httpe.WriteSafeErr but can be
-// overridden with an option passed to NewHandler.
+// overridden with an option passed to NewHandler. An error that wraps
+// ErrHandled is ignored.
+//
+// Before the error is written, it is classified by DefaultClassifier, or
+// the Classifier passed with WithClassifier, to map well-known errors to a
+// StatusError. The relative Location of a Redirect is then resolved
+// against the request path. Any ErrObservers passed as options are
+// notified of the original error after it is written.
+//
+// If h is a RequestHandlerE, such as a Chain, the ErrWriter and the
+// ErrObservers are passed the request derived by h, so that they can see
+// the context values set by h, such as the principal of an authentication
+// handler.
+//
+// The http.ResponseWriter passed to h and the ErrWriter implements
+// ResponseState, as well as the http.Flusher, http.Hijacker and http.Pusher
+// interfaces implemented by the original http.ResponseWriter. If h commits
+// the response before returning an error, the error is still passed to the
+// ErrWriter, which should not write the response again. The built-in
+// ErrWriters write nothing for a committed response. ErrObservers are
+// notified of the status code of the committed response.
 func NewHandler(h HandlerE, opts ...option) http.Handler {
 	o := newOptions(opts)
+	if o.recover {
+		h = Recover(h)
+	}
 	f := func(w http.ResponseWriter, r *http.Request) {
-		if err := h.ServeHTTPe(w, r); err != nil {
-			o.ew.WriteErr(w, err)
+		w = wrapResponseWriter(w)
+		r, err := serve(h, w, r)
+		if err != nil && !errors.Is(err, ErrHandled) {
+			cErr := resolveRedirect(r, o.classifier.Classify(err))
+			o.ew.WriteRequestErr(w, r, cErr)
+			status := w.(ResponseState).Status()
+			if status == 0 {
+				status = StatusCode(cErr)
+			}
+			for _, obs := range o.observers {
+				obs.ObserveErr(r, err, status)
+			}
 		}
 	}
 	return http.HandlerFunc(f)
@@ -148,12 +299,16 @@ func NewHandlerFunc(h HandlerFuncE, opts ...option) http.HandlerFunc { //nolint:
 type option func(*options)
 
 type options struct {
-	ew ErrWriter
+	ew         RequestErrWriter
+	observers  []ErrObserver
+	classifier *Classifier
+	recover    bool
 }
 
 func newOptions(opts []option) options {
 	o := options{
-		ew: ErrWriterFunc(WriteSafeErr),
+		ew:         errWriterAdapter(ErrWriterFunc(WriteSafeErr)),
+		classifier: DefaultClassifier,
 	}
 	for _, opt := range opts {
 		opt(&o)
@@ -165,29 +320,91 @@ func newOptions(opts []option) options {
 // for a HandlerE.
 func WithErrWriter(ew ErrWriter) option { //nolint:golint // Do not want to export option type.
 	return func(o *options) {
-		o.ew = ew
+		o.ew = errWriterAdapter(ew)
 	}
 }
 
 // WithErrWriterFunc returns an option to use the given ErrWriterFunc as the
 // ErrWriter for a HandlerE.
 func WithErrWriterFunc(f ErrWriterFunc) option { //nolint:golint // Do not want to export option type.
+	return func(o *options) {
+		o.ew = errWriterAdapter(f)
+	}
+}
+
+// WithRequestErrWriter returns an option to use the given RequestErrWriter
+// as the ErrWriter for a HandlerE.
+func WithRequestErrWriter(ew RequestErrWriter) option { //nolint:golint // Do not want to export option type.
+	return func(o *options) {
+		o.ew = ew
+	}
+}
+
+// WithRequestErrWriterFunc returns an option to use the given
+// RequestErrWriterFunc as the ErrWriter for a HandlerE.
+func WithRequestErrWriterFunc(f RequestErrWriterFunc) option { //nolint:golint // Do not want to export option type.
 	return func(o *options) {
 		o.ew = f
 	}
 }
 
+// WithErrObserver returns an option to add the given ErrObserver to the
+// observers notified of errors from a HandlerE.
+func WithErrObserver(obs ErrObserver) option { //nolint:golint // Do not want to export option type.
+	return func(o *options) {
+		o.observers = append(o.observers, obs)
+	}
+}
+
+// WithErrObserverFunc returns an option to add the given ErrObserverFunc to
+// the observers notified of errors from a HandlerE.
+func WithErrObserverFunc(f ErrObserverFunc) option { //nolint:golint // Do not want to export option type.
+	return WithErrObserver(f)
+}
+
+// WithClassifier returns an option to use the given Classifier in place of
+// DefaultClassifier to classify errors from a HandlerE before they are
+// written. A nil Classifier leaves errors unclassified.
+func WithClassifier(c *Classifier) option { //nolint:golint // Do not want to export option type.
+	return func(o *options) {
+		o.classifier = c
+	}
+}
+
+// WithRecover returns an option to recover from panics in a HandlerE,
+// passing the panic to the ErrWriter as a *PanicError. See Recover.
+func WithRecover() option { //nolint:golint // Do not want to export option type.
+	return func(o *options) {
+		o.recover = true
+	}
+}
+
+// errWriterAdapter turns an ErrWriter into a RequestErrWriter that ignores
+// the request.
+func errWriterAdapter(ew ErrWriter) RequestErrWriter {
+	f := func(w http.ResponseWriter, _ *http.Request, err error) {
+		ew.WriteErr(w, err)
+	}
+	return RequestErrWriterFunc(f)
+}
+
 // Chain returns a HandlerE that executes each of the HandlerFuncE parameters
 // sequentially, stopping at the first one that returns an error and returning
 // that error. It returns nil if none of the handlers return an error.
+//
+// A request derived by a RequestHandlerE in the chain is passed to the
+// handlers that follow it. The returned HandlerE is a RequestHandlerE that
+// derives the last request of the chain, so that derived requests are
+// passed on from nested chains.
 func Chain(he ...HandlerE) HandlerE {
-	f := func(w http.ResponseWriter, r *http.Request) error {
+	f := func(w http.ResponseWriter, r *http.Request) (*http.Request, error) {
 		for _, h := range he {
-			if err := h.ServeHTTPe(w, r); err != nil {
-				return err
+			var err error
+			if r, err = serve(h, w, r); err != nil {
+				return r, err
 			}
 		}
-		return nil
+		return r, nil
 	}
-	return HandlerFuncE(f)
+	return RequestHandlerFuncE(f)
 }
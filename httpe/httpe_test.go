@@ -140,3 +140,129 @@ func TestChain(t *testing.T) {
 	require.Equal(t, err, errHand)
 	require.Equal(t, count, 1)
 }
+
+func TestChainDerivedRequest(t *testing.T) {
+	key := NewContextKey[string]("step")
+	derive := func(step string) RequestHandlerFuncE {
+		return func(_ http.ResponseWriter, r *http.Request) (*http.Request, error) {
+			prev, _ := key.Value(r.Context())
+			return key.WithValue(r, prev+step), nil
+		}
+	}
+	unchanged := func(http.ResponseWriter, *http.Request) (*http.Request, error) { return nil, nil }
+	var got string
+	final := func(w http.ResponseWriter, r *http.Request) {
+		got, _ = key.Value(r.Context())
+	}
+
+	h := Chain(derive("a"), Chain(derive("b"), RequestHandlerFuncE(unchanged)), derive("c"), errHandler(nil), handlerAdapter(http.HandlerFunc(final)))
+	r := httptest.NewRequest("GET", "/", nil)
+	r2, err := h.(RequestHandlerE).ServeHTTPr(mock.ResponseWriter(), r)
+	require.NoError(t, err)
+	require.Equal(t, "abc", got)
+	v, _ := key.Value(r2.Context())
+	require.Equal(t, "abc", v)
+	_, ok := key.Value(r.Context())
+	require.False(t, ok)
+
+	got = ""
+	Must(derive("x"), unchanged, final).ServeHTTP(httptest.NewRecorder(), r)
+	require.Equal(t, "x", got)
+
+	errDerive := func(http.ResponseWriter, *http.Request) (*http.Request, error) { return nil, ErrForbidden }
+	w := httptest.NewRecorder()
+	got = ""
+	Must(derive("x"), errDerive, final).ServeHTTP(w, r)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Empty(t, got)
+}
+
+func TestNewDerivedRequestErr(t *testing.T) {
+	key := NewContextKey[string]("user")
+	auth := func(_ http.ResponseWriter, r *http.Request) (*http.Request, error) {
+		return key.WithValue(r, "kim"), nil
+	}
+	var written, observed string
+	ew := func(_ http.ResponseWriter, r *http.Request, _ error) {
+		written, _ = key.Value(r.Context())
+	}
+	obs := func(r *http.Request, _ error, _ int) {
+		observed, _ = key.Value(r.Context())
+	}
+	h := Must(auth, errHandler(ErrForbidden), ew, obs, WithRecover())
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, "kim", written)
+	require.Equal(t, "kim", observed)
+}
+
+func TestNewRequestErrWriter(t *testing.T) {
+	funcE := func(w http.ResponseWriter, _ *http.Request) error {
+		return ErrNotFound
+	}
+	ewFunc := func(w http.ResponseWriter, r *http.Request, err error) {
+		fmt.Fprint(w, r.URL.Path, ": ", err.Error())
+	}
+	ew := RequestErrWriterFunc(ewFunc)
+
+	for _, arg := range []interface{}{ewFunc, ew} {
+		h, err := New(funcE, arg)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/user", nil))
+		require.Equal(t, "/user: Not Found", w.Body.String())
+	}
+
+	_, err := New(ew, ErrWriterFunc(WriteSafeErr))
+	require.Error(t, err)
+}
+
+func TestHandlerWithRequestErrWriter(t *testing.T) {
+	he := &handlerE{}
+	h := NewHandler(he, WithRequestErrWriterFunc(WriteNegotiatedErr))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	h = NewHandler(he, WithRequestErrWriter(RequestErrWriterFunc(WriteNegotiatedErr)))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+}
+
+func TestErrObserver(t *testing.T) {
+	type observed struct {
+		path   string
+		err    error
+		status int
+	}
+	var got []observed
+	obsFunc := func(r *http.Request, err error, status int) {
+		got = append(got, observed{r.URL.Path, err, status})
+	}
+	errSecret := fmt.Errorf("%w: secret", ErrBadGateway)
+	funcE := func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Path == "/ok" {
+			return nil
+		}
+		return errSecret
+	}
+
+	h, err := New(funcE, obsFunc, ErrObserverFunc(obsFunc))
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/secret", nil))
+	require.Equal(t, http.StatusBadGateway, w.Code)
+	require.NotContains(t, w.Body.String(), "secret")
+	want := observed{"/secret", errSecret, http.StatusBadGateway}
+	require.Equal(t, []observed{want, want}, got)
+
+	got = nil
+	h = NewHandlerFunc(funcE, WithErrObserverFunc(obsFunc))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	require.Empty(t, got)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/secret", nil))
+	require.Equal(t, []observed{want}, got)
+}
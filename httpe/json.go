@@ -0,0 +1,157 @@
+package httpe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"foxygo.at/s/errs"
+)
+
+// Validator is implemented by request types of JSON handlers that validate
+// themselves after being decoded.
+type Validator interface {
+	Validate() error
+}
+
+// StatusCoder is implemented by response types of JSON handlers that set
+// the HTTP status code of the response, such as http.StatusCreated.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// JSON returns a HandlerE that decodes the JSON request body into a value
+// of type Req, calls f with it and encodes the value of type Resp returned
+// by f as the JSON response body.
+//
+// A request with a Content-Type other than application/json or a +json
+// suffixed type results in ErrUnsupportedMediaType. A request body that is
+// not valid JSON or does not match Req results in ErrBadRequest with a
+// message describing the problem. An empty request body leaves Req as its
+// zero value.
+//
+// If Req or *Req implements Validator, Validate is called before f, unless
+// Req is a pointer type and the request body is empty. Errors from
+// Validate that do not wrap a StatusError are wrapped with
+// ErrUnprocessableEntity.
+//
+// The response is written with status 200 OK, unless Resp implements
+// StatusCoder. No body is written for 204 No Content. Errors from f are
+// returned unchanged to be handled by the ErrWriter.
+func JSON[Req, Resp any](f func(context.Context, Req) (Resp, error)) HandlerE {
+	return jsonHandler[Req, Resp](f)
+}
+
+type jsonHandler[Req, Resp any] func(context.Context, Req) (Resp, error)
+
+// ServeHTTPe decodes the request, calls the handler function and encodes
+// its response.
+func (f jsonHandler[Req, Resp]) ServeHTTPe(w http.ResponseWriter, r *http.Request) error {
+	var req Req
+	if err := decodeJSON(r, &req); err != nil {
+		return err
+	}
+	if err := validate(&req); err != nil {
+		return err
+	}
+	resp, err := f(r.Context(), req)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(w, resp)
+}
+
+// types returns the request and response types of the handler, for
+// introspection by Router.
+func (f jsonHandler[Req, Resp]) types() (req, resp reflect.Type) {
+	return reflect.TypeFor[Req](), reflect.TypeFor[Resp]()
+}
+
+// decodeJSON decodes the JSON body of r into v, translating decoding
+// errors into client errors.
+func decodeJSON(r *http.Request, v interface{}) error {
+	if ct := r.Header.Get("Content-Type"); ct != "" && !isJSONMediaType(ct) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, ct)
+	}
+	if r.Body == nil {
+		return nil
+	}
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return jsonDecodeErr(err)
+	}
+	if dec.More() {
+		return fmt.Errorf("%w: unexpected data after JSON value", ErrBadRequest)
+	}
+	return nil
+}
+
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// jsonDecodeErr translates an error from decoding a JSON request body into
+// an error that wraps a StatusError with a message for the client.
+func jsonDecodeErr(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%w: invalid JSON at offset %d: %v", ErrBadRequest, syntaxErr.Offset, syntaxErr)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("%w: unexpected end of JSON", ErrBadRequest)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fmt.Errorf("%w: field %q: cannot use JSON %s as %s", ErrBadRequest, typeErr.Field, typeErr.Value, typeErr.Type)
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("%w: cannot use JSON %s as %s", ErrBadRequest, typeErr.Value, typeErr.Type)
+	}
+	return err
+}
+
+// validate calls Validate on *p if it implements Validator, or on p if it
+// does. Nothing is validated if *p is a nil pointer.
+func validate[T any](p *T) error {
+	val, ok := any(*p).(Validator)
+	if ok {
+		if v := reflect.ValueOf(*p); v.Kind() == reflect.Pointer && v.IsNil() {
+			return nil
+		}
+	} else if val, ok = any(p).(Validator); !ok {
+		return nil
+	}
+	err := val.Validate()
+	var sErr StatusError
+	if err == nil || errors.As(err, &sErr) {
+		return err
+	}
+	return errs.New(ErrUnprocessableEntity, err)
+}
+
+// encodeJSON writes v as the JSON response body with the status code from
+// v if it implements StatusCoder.
+func encodeJSON(w http.ResponseWriter, v interface{}) error {
+	status := http.StatusOK
+	if sc, ok := v.(StatusCoder); ok {
+		status = sc.StatusCode()
+	}
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return nil
+	}
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
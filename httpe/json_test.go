@@ -0,0 +1,147 @@
+package httpe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+type greetReq struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func (r *greetReq) Validate() error {
+	switch {
+	case r.Name == "nobody":
+		return fmt.Errorf("%w: nobody is forbidden", ErrForbidden)
+	case r.Age < 0:
+		return errors.New("age must not be negative")
+	}
+	return nil
+}
+
+type greetResp struct {
+	Greeting string `json:"greeting"`
+	status   int
+}
+
+func (r greetResp) StatusCode() int { return r.status }
+
+func greet(_ context.Context, req greetReq) (greetResp, error) {
+	switch req.Name {
+	case "":
+		return greetResp{status: http.StatusNoContent}, nil
+	case "error":
+		return greetResp{}, ErrTeapot
+	}
+	return greetResp{Greeting: "hello " + req.Name, status: http.StatusCreated}, nil
+}
+
+func serveJSON(h HandlerE, contentType, body string) (*httptest.ResponseRecorder, error) {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	return w, h.ServeHTTPe(w, r)
+}
+
+func TestJSON(t *testing.T) {
+	h := JSON(greet)
+	w, err := serveJSON(h, "application/json; charset=utf-8", `{"name": "kim", "age": 42}`)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"greeting": "hello kim"}`, w.Body.String())
+
+	w, err = serveJSON(h, "", ``)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Empty(t, w.Body.String())
+
+	_, err = serveJSON(h, "application/vnd.api+json", `{"name": "error"}`)
+	require.Equal(t, ErrTeapot, err)
+}
+
+func TestJSONDefaultStatus(t *testing.T) {
+	h := JSON(func(_ context.Context, req map[string]int) ([]int, error) {
+		return []int{req["a"], req["b"]}, nil
+	})
+	w, err := serveJSON(h, "", `{"a": 1, "b": 2}`)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `[1, 2]`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	err = h.ServeHTTPe(w, &http.Request{})
+	require.NoError(t, err)
+	require.JSONEq(t, `[0, 0]`, w.Body.String())
+}
+
+func TestJSONErr(t *testing.T) {
+	tests := map[string]struct {
+		contentType string
+		body        string
+		want        StatusError
+		msg         string
+	}{
+		"media type":   {"text/plain", `{}`, ErrUnsupportedMediaType, "Unsupported Media Type: text/plain"},
+		"bad type":     {"bad/type/", `{}`, ErrUnsupportedMediaType, "Unsupported Media Type: bad/type/"},
+		"syntax":       {"", `{"name": }`, ErrBadRequest, "Bad Request: invalid JSON at offset 10: invalid character '}' looking for beginning of value"},
+		"truncated":    {"", `{"name": "kim`, ErrBadRequest, "Bad Request: unexpected end of JSON"},
+		"field type":   {"", `{"age": "old"}`, ErrBadRequest, `Bad Request: field "age": cannot use JSON string as int`},
+		"value type":   {"", `[]`, ErrBadRequest, "Bad Request: cannot use JSON array as httpe.greetReq"},
+		"trailing":     {"", `{} {}`, ErrBadRequest, "Bad Request: unexpected data after JSON value"},
+		"validation":   {"", `{"age": -1}`, ErrUnprocessableEntity, "Unprocessable Entity: age must not be negative"},
+		"status error": {"", `{"name": "nobody"}`, ErrForbidden, "Forbidden: nobody is forbidden"},
+	}
+	h := JSON(greet)
+	for name, tc := range tests {
+		_, err := serveJSON(h, tc.contentType, tc.body)
+		require.True(t, errors.Is(err, tc.want), name)
+		require.Equal(t, tc.msg, err.Error(), name)
+	}
+}
+
+func TestJSONPointerValidate(t *testing.T) {
+	var got *greetReq
+	h := JSON(func(_ context.Context, req *greetReq) (struct{}, error) {
+		got = req
+		return struct{}{}, nil
+	})
+	_, err := serveJSON(h, "", `{"age": -1}`)
+	require.True(t, errors.Is(err, ErrUnprocessableEntity), err)
+
+	_, err = serveJSON(h, "", `{"name": "kim"}`)
+	require.NoError(t, err)
+	require.Equal(t, &greetReq{Name: "kim"}, got)
+
+	_, err = serveJSON(h, "", "")
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestJSONReadErr(t *testing.T) {
+	errRead := errors.New("read error")
+	r := httptest.NewRequest("POST", "/", iotest.ErrReader(errRead))
+	err := JSON(greet).ServeHTTPe(httptest.NewRecorder(), r)
+	require.Equal(t, errRead, err)
+}
+
+func TestJSONEncodeErr(t *testing.T) {
+	h := JSON(func(context.Context, struct{}) (chan int, error) {
+		return make(chan int), nil
+	})
+	w, err := serveJSON(h, "", "")
+	require.Error(t, err)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Body.String())
+}
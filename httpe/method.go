@@ -1,50 +1,127 @@
 package httpe
 
-import "net/http"
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
 
 var (
 	// Get is a HandlerE that returns a ErrMethodNotAllowed if the request
 	// method is not GET. Use with Chain or New/Must.
-	Get = newMethodChecker(http.MethodGet)
+	Get = methodChecker(http.MethodGet)
 
 	// Head is a HandlerE that returns a ErrMethodNotAllowed if the request
 	// method is not HEAD. Use with Chain or New/Must.
-	Head = newMethodChecker(http.MethodHead)
+	Head = methodChecker(http.MethodHead)
 
 	// Post is a HandlerE that returns a ErrMethodNotAllowed if the request
 	// method is not POST. Use with Chain or New/Must.
-	Post = newMethodChecker(http.MethodPost)
+	Post = methodChecker(http.MethodPost)
 
 	// Put is a HandlerE that returns a ErrMethodNotAllowed if the request
 	// method is not PUT. Use with Chain or New/Must.
-	Put = newMethodChecker(http.MethodPut)
+	Put = methodChecker(http.MethodPut)
 
 	// Patch is a HandlerE that returns a ErrMethodNotAllowed if the
 	// request method is not PATCH. Use with Chain or New/Must.
-	Patch = newMethodChecker(http.MethodPatch)
+	Patch = methodChecker(http.MethodPatch)
 
 	// Delete is a HandlerE that returns a ErrMethodNotAllowed if the
 	// request method is not DELETE. Use with Chain or New/Must.
-	Delete = newMethodChecker(http.MethodDelete)
+	Delete = methodChecker(http.MethodDelete)
 
 	// Connect is a HandlerE that returns a ErrMethodNotAllowed if the
 	// request method is not CONNECT. Use with Chain or New/Must.
-	Connect = newMethodChecker(http.MethodConnect)
+	Connect = methodChecker(http.MethodConnect)
 
 	// Options is a HandlerE that returns a ErrMethodNotAllowed if the
 	// request method is not OPTIONS. Use with Chain or New/Must.
-	Options = newMethodChecker(http.MethodOptions)
+	Options = methodChecker(http.MethodOptions)
 
 	// Trace is a HandlerE that returns a ErrMethodNotAllowed if the
 	// request method is not TRACE. Use with Chain or New/Must.
-	Trace = newMethodChecker(http.MethodTrace)
+	Trace = methodChecker(http.MethodTrace)
 )
 
-func newMethodChecker(method string) HandlerFuncE {
-	return HandlerFuncE(func(_ http.ResponseWriter, r *http.Request) error {
-		if r.Method != method {
-			return ErrMethodNotAllowed
+// methodChecker is a HandlerE that returns ErrMethodNotAllowed if the
+// request method is not its method. A Router takes the method of a route
+// from the methodChecker of the route if its pattern has no method.
+type methodChecker string
+
+// ServeHTTPe returns ErrMethodNotAllowed if the request method is not m.
+func (m methodChecker) ServeHTTPe(_ http.ResponseWriter, r *http.Request) error {
+	if r.Method != string(m) {
+		return ErrMethodNotAllowed
+	}
+	return nil
+}
+
+// String returns the method of m and implements fmt.Stringer.
+func (m methodChecker) String() string { return string(m) }
+
+// Methods is a HandlerE that dispatches a request to the HandlerE mapped
+// to the request method. Use with Chain or New/Must.
+//
+// A HEAD request is dispatched to the GET handler if there is no handler
+// for HEAD. An OPTIONS request is answered with a 204 No Content response
+// listing the allowed methods in the Allow header if there is no handler
+// for OPTIONS. Any other method without a handler results in a
+// MethodNotAllowedError so that the ErrWriter can write the Allow header.
+type Methods map[string]HandlerE
+
+// ServeHTTPe calls the HandlerE for the request method and returns its
+// error.
+func (m Methods) ServeHTTPe(w http.ResponseWriter, r *http.Request) error {
+	if h, ok := m[r.Method]; ok {
+		return h.ServeHTTPe(w, r)
+	}
+	switch r.Method {
+	case http.MethodHead:
+		if h, ok := m[http.MethodGet]; ok {
+			return h.ServeHTTPe(w, r)
 		}
+	case http.MethodOptions:
+		w.Header().Set("Allow", strings.Join(m.Allow(), ", "))
+		w.WriteHeader(http.StatusNoContent)
 		return nil
-	})
+	}
+	return &MethodNotAllowedError{Allow: m.Allow()}
+}
+
+// Allow returns the sorted list of methods allowed by m, including the
+// implicit HEAD and OPTIONS methods.
+func (m Methods) Allow() []string {
+	allow := make([]string, 0, len(m)+2)
+	for method := range m {
+		allow = append(allow, method)
+	}
+	_, hasGet := m[http.MethodGet]
+	if _, ok := m[http.MethodHead]; !ok && hasGet {
+		allow = append(allow, http.MethodHead)
+	}
+	if _, ok := m[http.MethodOptions]; !ok {
+		allow = append(allow, http.MethodOptions)
+	}
+	sort.Strings(allow)
+	return allow
+}
+
+// MethodNotAllowedError is an error that wraps ErrMethodNotAllowed and
+// carries the methods that are allowed for the requested resource, which
+// are written as the Allow header of the response.
+type MethodNotAllowedError struct {
+	Allow []string
+}
+
+// Error returns the error message and implements the error interface.
+func (err *MethodNotAllowedError) Error() string { return ErrMethodNotAllowed.Error() }
+
+// Unwrap returns ErrMethodNotAllowed.
+func (err *MethodNotAllowedError) Unwrap() error { return ErrMethodNotAllowed }
+
+// ResponseHeader returns the Allow header of err and implements
+// HeaderCarrier.
+func (err *MethodNotAllowedError) ResponseHeader() http.Header {
+	return http.Header{"Allow": {strings.Join(err.Allow, ", ")}}
 }
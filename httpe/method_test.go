@@ -10,7 +10,7 @@ import (
 )
 
 func TestEnsureMethod(t *testing.T) {
-	tests := map[string]HandlerFuncE{
+	tests := map[string]HandlerE{
 		http.MethodGet:     Get,
 		http.MethodHead:    Head,
 		http.MethodPost:    Post,
@@ -34,3 +34,47 @@ func TestEnsureMethod(t *testing.T) {
 	require.Error(t, err)
 	require.True(t, errors.Is(err, ErrMethodNotAllowed))
 }
+
+func TestMethods(t *testing.T) {
+	handler := func(body string) HandlerE {
+		return HandlerFuncE(func(w http.ResponseWriter, _ *http.Request) error {
+			_, err := w.Write([]byte(body))
+			return err
+		})
+	}
+	m := Methods{
+		http.MethodGet:  handler("get"),
+		http.MethodPost: handler("post"),
+	}
+	tests := map[string]string{
+		http.MethodGet:  "get",
+		http.MethodHead: "get",
+		http.MethodPost: "post",
+	}
+	for method, want := range tests {
+		w := mock.ResponseWriter()
+		err := m.ServeHTTPe(w, &http.Request{Method: method})
+		require.NoError(t, err)
+		require.Equal(t, want, w.LastBody)
+	}
+
+	w := mock.ResponseWriter()
+	err := m.ServeHTTPe(w, &http.Request{Method: http.MethodOptions})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, w.LastStatus)
+	require.Equal(t, "GET, HEAD, OPTIONS, POST", w.Header().Get("Allow"))
+
+	err = m.ServeHTTPe(w, &http.Request{Method: http.MethodDelete})
+	require.True(t, errors.Is(err, ErrMethodNotAllowed))
+	var mErr *MethodNotAllowedError
+	require.True(t, errors.As(err, &mErr))
+	require.Equal(t, []string{"GET", "HEAD", "OPTIONS", "POST"}, mErr.Allow)
+	require.Equal(t, ErrMethodNotAllowed.Error(), err.Error())
+}
+
+func TestMethodsNoGet(t *testing.T) {
+	m := Methods{http.MethodPut: Put}
+	require.Equal(t, []string{"OPTIONS", "PUT"}, m.Allow())
+	err := m.ServeHTTPe(mock.ResponseWriter(), &http.Request{Method: http.MethodHead})
+	require.True(t, errors.Is(err, ErrMethodNotAllowed))
+}
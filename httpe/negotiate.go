@@ -0,0 +1,113 @@
+package httpe
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// negotiatedErrWriters maps the media types offered by WriteNegotiatedErr to
+// the ErrWriter for that media type, in order of preference.
+var negotiatedErrWriters = []struct {
+	mediaType string
+	ew        ErrWriterFunc
+}{
+	{"text/plain", WriteSafeErr},
+	{"application/json", WriteJSONErr},
+	{"application/problem+json", WriteProblem},
+	{"text/html", WriteHTMLErr},
+}
+
+// WriteNegotiatedErr writes err as an HTTP error to the http.ResponseWriter
+// in the format that best matches the Accept header of the request r. It is
+// a RequestErrWriterFunc.
+//
+// The formats offered are plain text as written by WriteSafeErr, JSON as
+// written by WriteJSONErr, problem details as written by WriteProblem and
+// HTML as written by WriteHTMLErr. If the request has no Accept header or
+// accepts none of these formats, the error is written with WriteSafeErr.
+func WriteNegotiatedErr(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil || Committed(w) {
+		return
+	}
+	offers := make([]string, len(negotiatedErrWriters))
+	for i, nw := range negotiatedErrWriters {
+		offers[i] = nw.mediaType
+	}
+	w.Header().Add("Vary", "Accept")
+	mediaType := negotiate(r.Header.Get("Accept"), offers)
+	for _, nw := range negotiatedErrWriters {
+		if nw.mediaType == mediaType {
+			nw.ew(w, err)
+			return
+		}
+	}
+	WriteSafeErr(w, err)
+}
+
+// negotiate returns the offered media type that best matches the accept
+// header value as described in RFC 7231 section 5.3.2. Ties are broken by
+// the order of offers. If accept is empty, the first offer is returned. If
+// no offer is acceptable, the empty string is returned.
+func negotiate(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" && len(offers) > 0 {
+		return offers[0]
+	}
+	ranges := parseAccept(accept)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// mediaRange is a media range and its quality value from an Accept header.
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept parses the media ranges of an Accept header value, skipping
+// any that are malformed.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, s := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(s)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(qs, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality value of the most specific media range
+// matching mediaType, or 0 if none matches.
+func acceptQuality(ranges []mediaRange, mediaType string) float64 {
+	q, specificity := 0.0, -1
+	typ := strings.SplitN(mediaType, "/", 2)[0]
+	for _, mr := range ranges {
+		s := -1
+		switch mr.mediaType {
+		case mediaType:
+			s = 2
+		case typ + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = mr.q, s
+		}
+	}
+	return q
+}
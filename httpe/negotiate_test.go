@@ -0,0 +1,57 @@
+package httpe
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"text/plain", "application/json", "text/html"}
+	tests := map[string]string{
+		"":                                  "text/plain",
+		"*/*":                               "text/plain",
+		"application/json":                  "application/json",
+		"text/*":                            "text/plain",
+		"text/*;q=0.5, text/html":           "text/html",
+		"text/html;q=0.1, application/json": "application/json",
+		"text/plain;q=0, */*;q=0.1":         "application/json",
+		"image/png":                         "",
+		"text/html;q=x, application/json":   "application/json",
+		"text/html, %%%":                    "text/html",
+		"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8": "text/html",
+	}
+	for accept, want := range tests {
+		require.Equal(t, want, negotiate(accept, offers), "Accept: %s", accept)
+	}
+	require.Equal(t, "", negotiate("", nil))
+}
+
+func TestWriteNegotiatedErr(t *testing.T) {
+	tests := map[string]string{
+		"":                         "text/plain; charset=utf-8",
+		"image/png":                "text/plain; charset=utf-8",
+		"application/json":         "application/json",
+		"application/problem+json": "application/problem+json",
+		"application/*":            "application/json",
+		"text/html":                "text/html; charset=utf-8",
+	}
+	for accept, want := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", accept)
+		WriteNegotiatedErr(w, r, fmt.Errorf("%w: no such thing", ErrNotFound))
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, want, w.Header().Get("Content-Type"), "Accept: %s", accept)
+		require.Equal(t, "Accept", w.Header().Get("Vary"))
+		require.Contains(t, w.Body.String(), "no such thing")
+	}
+
+	w := httptest.NewRecorder()
+	WriteNegotiatedErr(w, httptest.NewRequest("GET", "/", nil), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header())
+}
@@ -0,0 +1,31 @@
+package httpe
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// SlogErrObserver returns an ErrObserverFunc that logs errors to logger.
+// Server errors are logged at error level and client errors at info
+// level. The full error is logged, including the details hidden from the
+// client, and the stack trace of a recovered panic.
+func SlogErrObserver(logger *slog.Logger) ErrObserverFunc {
+	return func(r *http.Request, err error, status int) {
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.String("error", err.Error()),
+		}
+		var pErr *PanicError
+		if errors.As(err, &pErr) {
+			attrs = append(attrs, slog.String("stack", string(pErr.Stack)))
+		}
+		logger.LogAttrs(r.Context(), level, "http error", attrs...)
+	}
+}
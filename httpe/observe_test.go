@@ -0,0 +1,25 @@
+package httpe
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogErrObserver(t *testing.T) {
+	buf := &bytes.Buffer{}
+	obs := SlogErrObserver(slog.New(slog.NewTextHandler(buf, nil)))
+	r := httptest.NewRequest("GET", "/user", nil)
+
+	obs(r, fmt.Errorf("%w: no such user", ErrNotFound), http.StatusNotFound)
+	require.Contains(t, buf.String(), `level=INFO msg="http error" method=GET path=/user status=404 error="Not Found: no such user"`)
+
+	buf.Reset()
+	obs(r, &PanicError{Value: "💥", Stack: []byte("stack trace")}, http.StatusInternalServerError)
+	require.Contains(t, buf.String(), `level=ERROR msg="http error" method=GET path=/user status=500 error="Internal Server Error: panic: 💥" stack="stack trace"`)
+}
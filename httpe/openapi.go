@@ -0,0 +1,246 @@
+package httpe
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAPI returns an OpenAPI 3.0 document in JSON describing the routes of
+// rt, with the given title and version of the API.
+//
+// Each operation of a route with a Method is described with the path
+// parameters of its pattern, the JSON schema of its request and response
+// types if known, and its error status codes with a problem details
+// response. Operations without a Method are not included, and GET, HEAD
+// and DELETE operations have no request body. Named struct types are
+// described by component schemas named after the type, with a numeric
+// suffix for types of the same name from different packages or scopes.
+func (rt *Router) OpenAPI(title, version string) ([]byte, error) {
+	g := newSchemaGen()
+	paths := map[string]map[string]interface{}{}
+	for _, route := range rt.routes {
+		path, params := openAPIPath(route.Path)
+		for _, op := range route.Operations {
+			if op.Method == "" {
+				continue
+			}
+			if paths[path] == nil {
+				paths[path] = map[string]interface{}{}
+			}
+			paths[path][strings.ToLower(op.Method)] = g.operation(route, op, params)
+		}
+	}
+	doc := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": title, "version": version},
+		"paths":   paths,
+	}
+	if len(g.schemas) > 0 {
+		doc["components"] = map[string]interface{}{"schemas": g.schemas}
+	}
+	return json.Marshal(doc)
+}
+
+var pathParamRE = regexp.MustCompile(`\{([^}]*)\}`)
+
+// openAPIPath returns the OpenAPI path template and the path parameters
+// of the ServeMux pattern path.
+func openAPIPath(path string) (string, []interface{}) {
+	params := []interface{}{}
+	path = pathParamRE.ReplaceAllStringFunc(path, func(s string) string {
+		name := strings.TrimSuffix(s[1:len(s)-1], "...")
+		if name == "$" {
+			return ""
+		}
+		params = append(params, map[string]interface{}{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+		return "{" + name + "}"
+	})
+	return path, params
+}
+
+// schemaGen generates JSON schemas for Go types, collecting the schemas
+// of named struct types as components.
+type schemaGen struct {
+	schemas map[string]interface{}
+	names   map[reflect.Type]string
+}
+
+func newSchemaGen() *schemaGen {
+	return &schemaGen{schemas: map[string]interface{}{}, names: map[reflect.Type]string{}}
+}
+
+// bodyless are the methods whose requests have no body in OpenAPI.
+var bodyless = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodHead:   true,
+	http.MethodDelete: true,
+}
+
+func (g *schemaGen) operation(route *Route, op Operation, params []interface{}) map[string]interface{} {
+	o := map[string]interface{}{}
+	if route.Summary != "" {
+		o["summary"] = route.Summary
+	}
+	if len(params) > 0 {
+		o["parameters"] = params
+	}
+	if op.Request != nil && !bodyless[op.Method] {
+		o["requestBody"] = map[string]interface{}{
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": g.schema(op.Request)},
+			},
+		}
+	}
+	success := map[string]interface{}{"description": http.StatusText(op.Status)}
+	if op.Response != nil && op.Status != http.StatusNoContent {
+		success["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": g.schema(op.Response)},
+		}
+	}
+	responses := map[string]interface{}{strconv.Itoa(op.Status): success}
+	for _, e := range route.allErrors(op) {
+		problem := g.component(problemType, func() map[string]interface{} { return problemSchema })
+		responses[strconv.Itoa(e.Code())] = map[string]interface{}{
+			"description": e.Error(),
+			"content": map[string]interface{}{
+				"application/problem+json": map[string]interface{}{"schema": problem},
+			},
+		}
+	}
+	o["responses"] = responses
+	return o
+}
+
+var problemSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"type":     map[string]interface{}{"type": "string"},
+		"title":    map[string]interface{}{"type": "string"},
+		"status":   map[string]interface{}{"type": "integer"},
+		"detail":   map[string]interface{}{"type": "string"},
+		"instance": map[string]interface{}{"type": "string"},
+	},
+}
+
+var (
+	timeType           = reflect.TypeFor[time.Time]()
+	problemType        = reflect.TypeFor[Problem]()
+	jsonMarshalerType  = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType  = reflect.TypeFor[encoding.TextMarshaler]()
+	invalidSchemaChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+)
+
+// schema returns the JSON schema of values of type t when encoded with
+// encoding/json.
+func (g *schemaGen) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return map[string]interface{}{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return map[string]interface{}{"type": "string"}
+	}
+	switch t.Kind() { //nolint:exhaustive // Remaining kinds have no schema.
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema returns the schema of a struct type, as a reference to a
+// component schema if the type is named.
+func (g *schemaGen) structSchema(t reflect.Type) map[string]interface{} {
+	if t.Name() == "" {
+		return g.objectSchema(t)
+	}
+	return g.component(t, func() map[string]interface{} { return g.objectSchema(t) })
+}
+
+// component returns a reference to the component schema of the named type
+// t, adding the schema returned by schema if t has none yet. The schema is
+// named after t, with a numeric suffix if the name is already taken by
+// another type.
+func (g *schemaGen) component(t reflect.Type, schema func() map[string]interface{}) map[string]interface{} {
+	name, ok := g.names[t]
+	if !ok {
+		base := invalidSchemaChars.ReplaceAllString(t.Name(), "_")
+		name = base
+		for i := 2; g.taken(name); i++ {
+			name = base + strconv.Itoa(i)
+		}
+		g.names[t] = name
+		g.schemas[name] = nil // Placeholder for recursive types.
+		g.schemas[name] = schema()
+	}
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func (g *schemaGen) taken(name string) bool {
+	_, ok := g.schemas[name]
+	return ok
+}
+
+func (g *schemaGen) objectSchema(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	g.addProperties(props, t)
+	return map[string]interface{}{"type": "object", "properties": props}
+}
+
+// addProperties adds the properties of the fields of the struct type t to
+// props, including the fields of embedded structs without a JSON name.
+func (g *schemaGen) addProperties(props map[string]interface{}, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			g.addProperties(props, ft)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+	}
+}
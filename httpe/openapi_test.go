@@ -0,0 +1,148 @@
+package httpe
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPI(t *testing.T) {
+	b, err := newTestRouter().OpenAPI("Items", "1.0")
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &doc))
+	require.Equal(t, "3.0.3", doc["openapi"])
+	require.Equal(t, map[string]interface{}{"title": "Items", "version": "1.0"}, doc["info"])
+
+	paths := doc["paths"].(map[string]interface{})
+	keys := make([]string, 0, len(paths))
+	for k := range paths {
+		keys = append(keys, k)
+	}
+	require.ElementsMatch(t, []string{"/items/{id}", "/items/", "/files/{path}", "/empty", "/create"}, keys)
+
+	getItem := `{
+		"summary": "Get an item",
+		"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+		"responses": {
+			"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/item"}}}},
+			"400": {"description": "Bad Request", "content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}},
+			"404": {"description": "Not Found", "content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}},
+			"405": {"description": "Method Not Allowed", "content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}},
+			"415": {"description": "Unsupported Media Type", "content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}}
+		}
+	}`
+	got, err := json.Marshal(paths["/items/{id}"].(map[string]interface{})["get"])
+	require.NoError(t, err)
+	require.JSONEq(t, getItem, string(got))
+
+	items := paths["/items/"].(map[string]interface{})
+	require.Contains(t, items["post"], "requestBody")
+	require.NotContains(t, items["delete"], "requestBody")
+	deleteResp := items["delete"].(map[string]interface{})["responses"].(map[string]interface{})["204"]
+	require.Equal(t, map[string]interface{}{"description": "No Content"}, deleteResp)
+
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	itemSchema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id":   map[string]interface{}{"type": "string"},
+			"name": map[string]interface{}{"type": "string"},
+		},
+	}
+	require.Equal(t, itemSchema, schemas["item"])
+	require.Equal(t, itemSchema, schemas["createItemResp"])
+
+	rt := NewRouter()
+	rt.Handle("/any", errHandler(nil))
+	b, err = rt.OpenAPI("Empty", "0")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"openapi": "3.0.3", "info": {"title": "Empty", "version": "0"}, "paths": {}}`, string(b))
+}
+
+type node struct {
+	Value    int       `json:"value"`
+	Children []*node   `json:"children,omitempty"`
+	Parent   *node     `json:"-"`
+	Created  time.Time `json:"created"`
+	secret   string
+	*meta
+}
+
+type meta struct {
+	Labels map[string]string `json:"labels"`
+	Raw    json.RawMessage   `json:"raw"`
+	IP     net.IP            `json:"ip"`
+	Data   []byte            `json:"data"`
+	Score  float64
+	OK     bool            `json:"ok"`
+	Any    interface{}     `json:"any"`
+	Grid   [2][2]uint8     `json:"grid"`
+	Page   page[string]    `json:"page"`
+	Inline struct{ X int } `json:"inline"`
+	Func   func()          `json:"-"`
+	Ch     chan int        `json:"ch"`
+}
+
+type page[T any] struct {
+	Items []T `json:"items"`
+}
+
+func TestSchema(t *testing.T) {
+	g := newSchemaGen()
+	require.Equal(t, map[string]interface{}{"$ref": "#/components/schemas/node"}, g.schema(reflect.TypeFor[*node]()))
+	b, err := json.Marshal(g.schemas)
+	require.NoError(t, err)
+	want := `{
+		"node": {"type": "object", "properties": {
+			"value": {"type": "integer"},
+			"children": {"type": "array", "items": {"$ref": "#/components/schemas/node"}},
+			"created": {"type": "string", "format": "date-time"},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"raw": {},
+			"ip": {"type": "string"},
+			"data": {"type": "string", "format": "byte"},
+			"Score": {"type": "number"},
+			"ok": {"type": "boolean"},
+			"any": {},
+			"grid": {"type": "array", "items": {"type": "array", "items": {"type": "integer"}}},
+			"page": {"$ref": "#/components/schemas/page_string_"},
+			"inline": {"type": "object", "properties": {"X": {"type": "integer"}}},
+			"ch": {}
+		}},
+		"page_string_": {"type": "object", "properties": {"items": {"type": "array", "items": {"type": "string"}}}}
+	}`
+	require.JSONEq(t, want, string(b))
+}
+
+func TestOpenAPISchemaNames(t *testing.T) {
+	type item struct {
+		Count int `json:"count"`
+	}
+	type Problem struct {
+		Code string `json:"code"`
+	}
+	rt := NewRouter()
+	rt.Handle("POST /a", JSON(func(context.Context, struct{}) (item, error) { return item{}, nil }))
+	rt.Handle("POST /b", JSON(func(context.Context, Problem) (*item, error) { return nil, nil }))
+	rt.Handle("POST /c", JSON(getItem))
+	b, err := rt.OpenAPI("Names", "1.0")
+	require.NoError(t, err)
+	var doc struct {
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(b, &doc))
+	schemas := doc.Components.Schemas
+	require.Len(t, schemas, 4)
+	require.JSONEq(t, `{"type": "object", "properties": {"count": {"type": "integer"}}}`, string(schemas["item"]))
+	require.Contains(t, string(schemas["item2"]), `"name"`)
+	require.Contains(t, string(schemas["Problem"]), `"detail"`)
+	require.JSONEq(t, `{"type": "object", "properties": {"code": {"type": "string"}}}`, string(schemas["Problem2"]))
+}
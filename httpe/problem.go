@@ -0,0 +1,165 @@
+package httpe
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Problem is a problem details document as described in RFC 7807. It is
+// written as the body of an error response by WriteProblem.
+//
+// Extensions holds additional members of the problem details document.
+// Members with the name of a standard member are ignored.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+}
+
+// ProblemExtender is implemented by errors that contribute to the problem
+// details document written for them, typically by adding Extensions or
+// setting the Type or Instance of the Problem.
+//
+// ExtendProblem is only called for client errors so that a server error
+// does not leak internal details to the client.
+type ProblemExtender interface {
+	ExtendProblem(*Problem)
+}
+
+// MarshalJSON marshals p as a JSON object with the standard members
+// omitted if they have their zero value.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+	setNonZero(m, "type", p.Type, p.Type != "")
+	setNonZero(m, "title", p.Title, p.Title != "")
+	setNonZero(m, "status", p.Status, p.Status != 0)
+	setNonZero(m, "detail", p.Detail, p.Detail != "")
+	setNonZero(m, "instance", p.Instance, p.Instance != "")
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON unmarshals a problem details document into p, storing
+// members other than the standard members in Extensions.
+func (p *Problem) UnmarshalJSON(b []byte) error {
+	var doc struct {
+		Type     string `json:"type"`
+		Title    string `json:"title"`
+		Status   int    `json:"status"`
+		Detail   string `json:"detail"`
+		Instance string `json:"instance"`
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+	for _, k := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(m, k)
+	}
+	if len(m) == 0 {
+		m = nil
+	}
+	*p = Problem{
+		Type:       doc.Type,
+		Title:      doc.Title,
+		Status:     doc.Status,
+		Detail:     doc.Detail,
+		Instance:   doc.Instance,
+		Extensions: m,
+	}
+	return nil
+}
+
+func setNonZero(m map[string]interface{}, key string, val interface{}, nonZero bool) {
+	if nonZero {
+		m[key] = val
+	} else {
+		delete(m, key)
+	}
+}
+
+// NewProblem returns the Problem describing err, applying the same rules
+// as WriteSafeErr to hide the details of server errors.
+//
+// If err wraps a StatusError, the Status and Title of the Problem are set
+// from that StatusError, otherwise from ErrInternalServerError. For client
+// errors (code 400 to 499), Detail is set to the error string if it adds
+// anything to the Title, and every error in the tree of err that implements
+// ProblemExtender is given the opportunity to extend the Problem, with
+// outer errors applied after the errors they wrap.
+func NewProblem(err error) *Problem {
+	sErr := ErrInternalServerError
+	ok := errors.As(err, &sErr)
+	p := &Problem{Title: sErr.Error(), Status: sErr.Code()}
+	if !ok || !sErr.IsClientError() {
+		return p
+	}
+	if msg := err.Error(); msg != p.Title {
+		p.Detail = msg
+	}
+	var extenders []ProblemExtender
+	walkErr(err, func(e error) {
+		if pe, ok := e.(ProblemExtender); ok {
+			extenders = append(extenders, pe)
+		}
+	})
+	for i := len(extenders) - 1; i >= 0; i-- {
+		extenders[i].ExtendProblem(p)
+	}
+	return p
+}
+
+// WriteProblem writes err as an HTTP error to the http.ResponseWriter
+// with a body of an RFC 7807 problem details document as returned by
+// NewProblem, using the application/problem+json content type.
+//
+// If the Extensions of the Problem cannot be marshaled to JSON, the
+// document is written without them. For a redirect, such as a Redirect,
+// only the status code and headers are written.
+func WriteProblem(w http.ResponseWriter, err error) {
+	if err == nil || Committed(w) {
+		return
+	}
+	SetErrHeaders(w.Header(), err)
+	if writeRedirect(w, err) {
+		return
+	}
+	writeProblem(w, NewProblem(err), "application/problem+json")
+}
+
+// WriteJSONErr writes err as an HTTP error to the http.ResponseWriter with
+// a body of the same JSON document as WriteProblem, but using the
+// application/json content type for clients that do not understand
+// application/problem+json. For a redirect, only the status code and
+// headers are written.
+func WriteJSONErr(w http.ResponseWriter, err error) {
+	if err == nil || Committed(w) {
+		return
+	}
+	SetErrHeaders(w.Header(), err)
+	if writeRedirect(w, err) {
+		return
+	}
+	writeProblem(w, NewProblem(err), "application/json")
+}
+
+func writeProblem(w http.ResponseWriter, p *Problem, contentType string) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		p.Extensions = nil
+		b, _ = json.Marshal(p)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_, _ = w.Write(append(b, '\n'))
+}
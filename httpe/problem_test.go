@@ -0,0 +1,145 @@
+package httpe
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type extErr struct {
+	key string
+	val interface{}
+}
+
+func (e extErr) Error() string { return "extended" }
+
+func (e extErr) ExtendProblem(p *Problem) {
+	if p.Extensions == nil {
+		p.Extensions = map[string]interface{}{}
+	}
+	p.Extensions[e.key] = e.val
+	p.Type = "https://example.com/" + e.key
+}
+
+type joinErr []error
+
+func (e joinErr) Error() string { return e[0].Error() }
+
+func (e joinErr) Unwrap() []error { return e }
+
+func TestProblemMarshal(t *testing.T) {
+	p := &Problem{
+		Type:       "https://example.com/probs/out-of-credit",
+		Title:      "You do not have enough credit.",
+		Status:     http.StatusForbidden,
+		Detail:     "Your current balance is 30, but that costs 50.",
+		Instance:   "/account/12345/msgs/abc",
+		Extensions: map[string]interface{}{"balance": 30, "status": "ignored"},
+	}
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	want := `{
+		"type": "https://example.com/probs/out-of-credit",
+		"title": "You do not have enough credit.",
+		"status": 403,
+		"detail": "Your current balance is 30, but that costs 50.",
+		"instance": "/account/12345/msgs/abc",
+		"balance": 30
+	}`
+	require.JSONEq(t, want, string(b))
+
+	b, err = json.Marshal(&Problem{Extensions: map[string]interface{}{"title": "ignored"}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{}`, string(b))
+}
+
+func TestProblemUnmarshal(t *testing.T) {
+	doc := `{
+		"type": "https://example.com/probs/out-of-credit",
+		"title": "You do not have enough credit.",
+		"status": 403,
+		"balance": 30
+	}`
+	var p Problem
+	require.NoError(t, json.Unmarshal([]byte(doc), &p))
+	want := Problem{
+		Type:       "https://example.com/probs/out-of-credit",
+		Title:      "You do not have enough credit.",
+		Status:     http.StatusForbidden,
+		Extensions: map[string]interface{}{"balance": 30.0},
+	}
+	require.Equal(t, want, p)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"title": "Not Found"}`), &p))
+	require.Equal(t, Problem{Title: "Not Found"}, p)
+
+	require.Error(t, json.Unmarshal([]byte(`[]`), &p))
+	require.Error(t, json.Unmarshal([]byte(`{"status": "403"}`), &p))
+}
+
+func TestNewProblem(t *testing.T) {
+	p := NewProblem(ErrNotFound)
+	require.Equal(t, &Problem{Title: "Not Found", Status: 404}, p)
+
+	p = NewProblem(fmt.Errorf("%w: no such user", ErrNotFound))
+	require.Equal(t, &Problem{Title: "Not Found", Status: 404, Detail: "Not Found: no such user"}, p)
+
+	p = NewProblem(errors.New("💥"))
+	require.Equal(t, &Problem{Title: "Internal Server Error", Status: 500}, p)
+
+	ext := extErr{key: "secret", val: 42}
+	p = NewProblem(joinErr{ErrBadGateway, ext})
+	require.Equal(t, &Problem{Title: "Bad Gateway", Status: 502}, p)
+}
+
+func TestNewProblemExtensions(t *testing.T) {
+	inner := extErr{key: "inner", val: 1}
+	outer := extErr{key: "outer", val: 2}
+	err := joinErr{ErrConflict, joinErr{fmt.Errorf("outer: %w", outer), inner}}
+	p := NewProblem(err)
+	require.Equal(t, map[string]interface{}{"inner": 1, "outer": 2}, p.Extensions)
+	require.Equal(t, "https://example.com/outer", p.Type)
+}
+
+func TestWriteProblem(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteProblem(w, fmt.Errorf("%w: secret details", ErrInternalServerError))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"title": "Internal Server Error", "status": 500}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	WriteProblem(w, &MethodNotAllowedError{Allow: []string{http.MethodGet}})
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	require.Equal(t, "GET", w.Header().Get("Allow"))
+	require.JSONEq(t, `{"title": "Method Not Allowed", "status": 405}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	WriteProblem(w, joinErr{ErrBadRequest, extErr{key: "bad", val: make(chan int)}})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	want := `{"type": "https://example.com/bad", "title": "Bad Request", "status": 400}`
+	require.JSONEq(t, want, w.Body.String())
+
+	w = httptest.NewRecorder()
+	WriteProblem(w, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Body.String())
+}
+
+func TestWriteJSONErr(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteJSONErr(w, fmt.Errorf("%w: missing name", ErrBadRequest))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"title": "Bad Request", "status": 400, "detail": "Bad Request: missing name"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	WriteJSONErr(w, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Body.String())
+}
@@ -0,0 +1,86 @@
+package httpe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+)
+
+// Proxy is a HandlerE that forwards requests with an
+// httputil.ReverseProxy and returns its failures as errors, so that they
+// are written by the ErrWriter and seen by the observers. Use with Chain
+// or New/Must.
+//
+// A failure to reach the upstream server or to read its response results
+// in an error wrapping ErrBadGateway, or ErrGatewayTimeout if it timed
+// out. If the client cancels the request, the error of the request
+// context is returned.
+//
+// The ErrorHandler of the ReverseProxy is not used.
+type Proxy struct {
+	ReverseProxy *httputil.ReverseProxy
+	// MapUpstreamErrors, if true, stops upstream responses with a 5xx
+	// status code from being forwarded to the client. The *ResponseError
+	// returned by CheckResponse for the upstream response is returned
+	// instead, so that the client gets an error response with the same
+	// status code written by the ErrWriter, without the upstream body.
+	MapUpstreamErrors bool
+}
+
+// NewProxy returns a Proxy that forwards requests to the scheme, host and
+// base path of target, setting the X-Forwarded-For, X-Forwarded-Host and
+// X-Forwarded-Proto headers.
+func NewProxy(target *url.URL) *Proxy {
+	rp := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+		},
+	}
+	return &Proxy{ReverseProxy: rp}
+}
+
+// ServeHTTPe forwards the request and returns the failure to do so.
+func (p *Proxy) ServeHTTPe(w http.ResponseWriter, r *http.Request) error {
+	var proxyErr error
+	rp := *p.ReverseProxy
+	rp.ErrorHandler = func(_ http.ResponseWriter, r *http.Request, err error) {
+		proxyErr = proxyError(r.Context(), err)
+	}
+	if p.MapUpstreamErrors {
+		modify := p.ReverseProxy.ModifyResponse
+		rp.ModifyResponse = func(resp *http.Response) error {
+			if resp.StatusCode >= 500 {
+				return CheckResponse(resp)
+			}
+			if modify != nil {
+				return modify(resp)
+			}
+			return nil
+		}
+	}
+	rp.ServeHTTP(w, r)
+	return proxyErr
+}
+
+// proxyError maps an error of a ReverseProxy to an error wrapping
+// ErrBadGateway or ErrGatewayTimeout. Errors that already wrap a
+// StatusError and errors caused by the client canceling the request are
+// returned unchanged.
+func proxyError(ctx context.Context, err error) error {
+	var sErr StatusError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &sErr):
+		return err
+	case errors.Is(err, context.Canceled) && errors.Is(ctx.Err(), context.Canceled):
+		return ctx.Err()
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("%w: %w", ErrGatewayTimeout, err)
+	}
+	return fmt.Errorf("%w: %w", ErrBadGateway, err)
+}
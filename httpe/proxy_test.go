@@ -0,0 +1,118 @@
+package httpe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newUpstream(t *testing.T) (*httptest.Server, *url.URL) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/hello", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "hello %s", r.Header.Get("X-Forwarded-Host"))
+	})
+	mux.HandleFunc("/api/down", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "database at 10.0.0.1 down", http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/api/missing", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "no such thing", http.StatusNotFound)
+	})
+	mux.HandleFunc("/api/slow", func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	u, err := url.Parse(s.URL + "/api")
+	require.NoError(t, err)
+	return s, u
+}
+
+func TestProxy(t *testing.T) {
+	_, u := newUpstream(t)
+	var gotErr error
+	obs := func(_ *http.Request, err error, _ int) { gotErr = err }
+	p := NewProxy(u)
+	h := Must(p, obs)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/hello", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "hello example.com", w.Body.String())
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/down", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, "database at 10.0.0.1 down\n", w.Body.String())
+
+	p.MapUpstreamErrors = true
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/down", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, "Service Unavailable\n", w.Body.String())
+	require.True(t, errors.Is(gotErr, ErrServiceUnavailable))
+	require.Equal(t, "Service Unavailable: database at 10.0.0.1 down", gotErr.Error())
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, "no such thing\n", w.Body.String())
+}
+
+func TestProxyModifyResponse(t *testing.T) {
+	_, u := newUpstream(t)
+	p := NewProxy(u)
+	p.MapUpstreamErrors = true
+	errModify := errors.New("cannot modify")
+	p.ReverseProxy.ModifyResponse = func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusNotFound {
+			return errModify
+		}
+		resp.Header.Set("X-Modified", "true")
+		return nil
+	}
+	w := httptest.NewRecorder()
+	require.NoError(t, p.ServeHTTPe(w, httptest.NewRequest("GET", "/hello", nil)))
+	require.Equal(t, "true", w.Header().Get("X-Modified"))
+
+	err := p.ServeHTTPe(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+	require.True(t, errors.Is(err, ErrBadGateway))
+	require.True(t, errors.Is(err, errModify))
+}
+
+func TestProxyErr(t *testing.T) {
+	s, u := newUpstream(t)
+	p := NewProxy(u)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	r := httptest.NewRequest("GET", "/slow", nil).WithContext(ctx)
+	err := p.ServeHTTPe(httptest.NewRecorder(), r)
+	require.True(t, errors.Is(err, ErrGatewayTimeout), err)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	r = httptest.NewRequest("GET", "/slow", nil).WithContext(ctx)
+	err = p.ServeHTTPe(httptest.NewRecorder(), r)
+	require.Equal(t, context.Canceled, err)
+
+	p.ReverseProxy.Transport = &http.Transport{ResponseHeaderTimeout: 10 * time.Millisecond}
+	err = p.ServeHTTPe(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+	require.True(t, errors.Is(err, ErrGatewayTimeout), err)
+
+	s.Close()
+	w := httptest.NewRecorder()
+	Must(p).ServeHTTP(w, httptest.NewRequest("GET", "/hello", nil))
+	require.Equal(t, http.StatusBadGateway, w.Code)
+	require.Equal(t, "Bad Gateway\n", w.Body.String())
+}
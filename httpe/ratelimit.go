@@ -0,0 +1,170 @@
+package httpe
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Limit is a rate limit of Requests per Period. Requests up to the limit
+// may be made in a burst, after which they are allowed at an even rate.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// LimitResult is the result of counting a request against a Limit.
+// Remaining is the number of requests that may be made immediately after
+// this one. Reset is the time until the full Limit is available again.
+// RetryAfter is the time until the next request is allowed if this one is
+// not.
+type LimitResult struct {
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// LimitStore counts requests against a Limit by key. Implementations must
+// be safe for concurrent use. MemoryLimitStore is an in-memory
+// implementation; other implementations may share the count between
+// servers.
+type LimitStore interface {
+	Take(ctx context.Context, key string, l Limit) (LimitResult, error)
+}
+
+// RateLimit returns a HandlerE that limits the rate of requests to l for
+// each key returned by the key function, such as ClientIP, counting
+// requests in store. Use with Chain or New/Must.
+//
+// The RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers are
+// set on the response. A request over the limit results in a HeaderError
+// for ErrTooManyRequests that also carries the Retry-After header. An error
+// from the store is returned unchanged.
+//
+// RateLimit panics if l does not allow at least one request in a positive
+// Period.
+func RateLimit(l Limit, key func(*http.Request) string, store LimitStore) Guard {
+	if l.Requests <= 0 || l.Period <= 0 {
+		panic(fmt.Sprintf("httpe: invalid rate limit of %d requests per %v", l.Requests, l.Period))
+	}
+	f := func(w http.ResponseWriter, r *http.Request) error {
+		res, err := store.Take(r.Context(), key(r), l)
+		if err != nil {
+			return err
+		}
+		h := http.Header{}
+		h.Set("RateLimit-Limit", strconv.Itoa(l.Requests))
+		h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(res.Reset), 10))
+		for k, v := range h {
+			w.Header()[k] = v
+		}
+		if res.Allowed {
+			return nil
+		}
+		hErr := RetryAfter(ErrTooManyRequests, res.RetryAfter)
+		for k, v := range h {
+			hErr.Header[k] = v
+		}
+		return hErr
+	}
+	return Guard{Name: fmt.Sprintf("RateLimit(%d/%v)", l.Requests, l.Period), Handler: HandlerFuncE(f)}
+}
+
+// ClientIP returns the IP address of the client that sent r, taken from
+// r.RemoteAddr. It can be used as the key function of RateLimit. Headers
+// set by proxies, such as X-Forwarded-For, are not used as they can be set
+// by the client.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// MemoryLimitStore is a LimitStore that counts requests in memory with a
+// token bucket for each key. Buckets that have refilled are removed
+// periodically so that memory use is bounded by the number of active keys.
+type MemoryLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// bucket is a token bucket for a Limit.
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// NewMemoryLimitStore returns an empty MemoryLimitStore.
+func NewMemoryLimitStore() *MemoryLimitStore {
+	return &MemoryLimitStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// Take counts a request for key against the Limit l and implements
+// LimitStore. It never returns an error.
+func (s *MemoryLimitStore) Take(_ context.Context, key string, l Limit) (LimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweep(now)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.Requests), last: now}
+		s.buckets[key] = b
+	}
+	b.limit = l
+	b.refill(now)
+	res := LimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = b.duration(1 - b.tokens)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = b.duration(float64(l.Requests) - b.tokens)
+	return res, nil
+}
+
+// sweep removes the buckets that have refilled, at most once a minute.
+func (s *MemoryLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if b.refill(now); b.tokens >= float64(b.limit.Requests) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// rate returns the rate at which tokens are added to b per second.
+func (b *bucket) rate() float64 {
+	return float64(b.limit.Requests) / b.limit.Period.Seconds()
+}
+
+// refill adds the tokens accrued since b was last refilled.
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(float64(b.limit.Requests), b.tokens+now.Sub(b.last).Seconds()*b.rate())
+	b.last = now
+}
+
+// duration returns the time it takes to accrue the given tokens.
+func (b *bucket) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / b.rate() * float64(time.Second))
+}
@@ -0,0 +1,107 @@
+package httpe
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestLimitStore() (*MemoryLimitStore, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := NewMemoryLimitStore()
+	s.now = clock.now
+	return s, clock
+}
+
+func TestMemoryLimitStore(t *testing.T) {
+	s, clock := newTestLimitStore()
+	l := Limit{Requests: 2, Period: 10 * time.Second}
+	ctx := context.Background()
+
+	res, err := s.Take(ctx, "a", l)
+	require.NoError(t, err)
+	require.Equal(t, LimitResult{Allowed: true, Remaining: 1, Reset: 5 * time.Second}, res)
+	res, _ = s.Take(ctx, "a", l)
+	require.Equal(t, LimitResult{Allowed: true, Remaining: 0, Reset: 10 * time.Second}, res)
+	res, _ = s.Take(ctx, "a", l)
+	require.Equal(t, LimitResult{Allowed: false, Remaining: 0, Reset: 10 * time.Second, RetryAfter: 5 * time.Second}, res)
+
+	res, _ = s.Take(ctx, "b", l)
+	require.True(t, res.Allowed)
+
+	clock.t = clock.t.Add(5 * time.Second)
+	res, _ = s.Take(ctx, "a", l)
+	require.Equal(t, LimitResult{Allowed: true, Remaining: 0, Reset: 10 * time.Second}, res)
+
+	clock.t = clock.t.Add(time.Minute)
+	res, _ = s.Take(ctx, "c", l)
+	require.True(t, res.Allowed)
+	require.Len(t, s.buckets, 1)
+}
+
+func TestRateLimit(t *testing.T) {
+	s, _ := newTestLimitStore()
+	h := RateLimit(Limit{Requests: 1, Period: 90 * time.Second}, ClientIP, s)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	require.NoError(t, h.ServeHTTPe(w, r))
+	require.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	require.Equal(t, "90", w.Header().Get("RateLimit-Reset"))
+
+	w = httptest.NewRecorder()
+	err := h.ServeHTTPe(w, r)
+	require.True(t, errors.Is(err, ErrTooManyRequests))
+	hdr := http.Header{}
+	SetErrHeaders(hdr, err)
+	want := http.Header{
+		"Retry-After":         {"90"},
+		"Ratelimit-Limit":     {"1"},
+		"Ratelimit-Remaining": {"0"},
+		"Ratelimit-Reset":     {"90"},
+	}
+	require.Equal(t, want, hdr)
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.2:1234"
+	require.NoError(t, h.ServeHTTPe(httptest.NewRecorder(), r))
+}
+
+type errLimitStore struct{}
+
+var errStore = errors.New("store unavailable")
+
+func (errLimitStore) Take(context.Context, string, Limit) (LimitResult, error) {
+	return LimitResult{}, errStore
+}
+
+func TestRateLimitStoreErr(t *testing.T) {
+	h := RateLimit(Limit{Requests: 1, Period: time.Second}, ClientIP, errLimitStore{})
+	err := h.ServeHTTPe(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, errStore, err)
+}
+
+func TestRateLimitInvalidLimit(t *testing.T) {
+	s := NewMemoryLimitStore()
+	require.Panics(t, func() { RateLimit(Limit{Requests: 0, Period: time.Second}, ClientIP, s) })
+	require.Panics(t, func() { RateLimit(Limit{Requests: 1}, ClientIP, s) })
+	require.Panics(t, func() { RateLimit(Limit{Requests: 1, Period: -time.Second}, ClientIP, s) })
+}
+
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "[::1]:8080"
+	require.Equal(t, "::1", ClientIP(r))
+	r.RemoteAddr = "pipe"
+	require.Equal(t, "pipe", ClientIP(r))
+}
@@ -0,0 +1,62 @@
+package httpe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicError is the error returned by a HandlerE created with Recover when
+// the HandlerE it wraps panics. It wraps ErrInternalServerError and holds
+// the value passed to panic and the stack trace of the panicking goroutine.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// Error returns the error message and implements the error interface.
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("%v: panic: %v", ErrInternalServerError, err.Value)
+}
+
+// Unwrap returns ErrInternalServerError.
+func (err *PanicError) Unwrap() error { return ErrInternalServerError }
+
+// Recover returns a HandlerE that calls h, recovering from any panic in h
+// and returning it as a *PanicError so that it is written by the ErrWriter
+// instead of unwinding into net/http. A panic with a *PanicError, such as
+// one propagated from another goroutine by Timeout, is returned as is. A
+// panic with http.ErrAbortHandler is not recovered as it is used to abort
+// a response deliberately. The request derived by h is passed on in a
+// Chain.
+func Recover(h HandlerE) HandlerE {
+	f := func(w http.ResponseWriter, r *http.Request) (r2 *http.Request, err error) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			pErr, ok := panicValue(v).(*PanicError)
+			if !ok {
+				panic(v)
+			}
+			err = pErr
+		}()
+		return serve(h, w, r)
+	}
+	return RequestHandlerFuncE(f)
+}
+
+// panicValue returns the value v recovered from a panic as a *PanicError
+// holding the stack trace of the panicking goroutine, unless it is
+// already a *PanicError or an http.ErrAbortHandler.
+func panicValue(v interface{}) interface{} {
+	if e, ok := v.(error); ok && errors.Is(e, http.ErrAbortHandler) {
+		return v
+	}
+	if _, ok := v.(*PanicError); ok {
+		return v
+	}
+	return &PanicError{Value: v, Stack: debug.Stack()}
+}
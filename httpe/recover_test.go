@@ -0,0 +1,62 @@
+package httpe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecover(t *testing.T) {
+	panicky := HandlerFuncE(func(http.ResponseWriter, *http.Request) error {
+		panic("💥")
+	})
+	err := Recover(panicky).ServeHTTPe(httptest.NewRecorder(), &http.Request{})
+	require.True(t, errors.Is(err, ErrInternalServerError))
+	var pErr *PanicError
+	require.True(t, errors.As(err, &pErr))
+	require.Equal(t, "💥", pErr.Value)
+	require.Contains(t, string(pErr.Stack), "TestRecover")
+	require.Equal(t, "Internal Server Error: panic: 💥", err.Error())
+
+	errBoom := errors.New("boom")
+	notPanicky := HandlerFuncE(func(http.ResponseWriter, *http.Request) error {
+		return errBoom
+	})
+	err = Recover(notPanicky).ServeHTTPe(httptest.NewRecorder(), &http.Request{})
+	require.Equal(t, errBoom, err)
+}
+
+func TestRecoverAbort(t *testing.T) {
+	abort := HandlerFuncE(func(http.ResponseWriter, *http.Request) error {
+		panic(fmt.Errorf("aborting: %w", http.ErrAbortHandler))
+	})
+	f := func() { _ = Recover(abort).ServeHTTPe(httptest.NewRecorder(), &http.Request{}) }
+	require.Panics(t, f)
+}
+
+func TestWithRecover(t *testing.T) {
+	panicky := func(http.ResponseWriter, *http.Request) error {
+		panic("secret")
+	}
+	var got error
+	ew := func(w http.ResponseWriter, err error) {
+		got = err
+		WriteSafeErr(w, err)
+	}
+	h, err := New(panicky, ew, WithRecover())
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.NotContains(t, w.Body.String(), "secret")
+	var pErr *PanicError
+	require.True(t, errors.As(got, &pErr))
+	require.Equal(t, "secret", pErr.Value)
+
+	h = NewHandlerFunc(panicky)
+	require.Panics(t, func() { h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)) })
+}
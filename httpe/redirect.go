@@ -0,0 +1,97 @@
+package httpe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Redirect is an error that redirects the client to Location with the 3xx
+// status code Code, such as http.StatusSeeOther. If Code is zero, or not
+// a 3xx code, http.StatusFound is used. It can be returned by a HandlerE to stop a
+// Chain with a redirect, such as to a login page.
+//
+// Redirect wraps the StatusError of its code and carries the Location
+// header. The built-in ErrWriters write the status code and headers of a
+// Redirect without a body. A Location that is a relative path is resolved
+// against the request path by NewHandler, as done by http.Redirect.
+type Redirect struct {
+	Code     int
+	Location string
+}
+
+// Error returns the error message and implements the error interface.
+func (err *Redirect) Error() string {
+	return fmt.Sprintf("%v: %s", err.Unwrap(), err.Location)
+}
+
+// Unwrap returns the StatusError of the redirect code.
+func (err *Redirect) Unwrap() error {
+	if err.Code < 300 || err.Code > 399 {
+		return StatusError(http.StatusFound)
+	}
+	return StatusError(err.Code)
+}
+
+// ResponseHeader returns the Location header of err and implements
+// HeaderCarrier.
+func (err *Redirect) ResponseHeader() http.Header {
+	return http.Header{"Location": {err.Location}}
+}
+
+// locationError overrides the Location header of the redirect it wraps
+// with the location resolved against the request.
+type locationError struct {
+	error
+	location string
+}
+
+// Unwrap returns the wrapped error.
+func (err *locationError) Unwrap() error { return err.error }
+
+// ResponseHeader returns the resolved Location header and implements
+// HeaderCarrier.
+func (err *locationError) ResponseHeader() http.Header {
+	return http.Header{"Location": {err.location}}
+}
+
+// resolveRedirect returns err wrapped with the Location of the Redirect in
+// its tree resolved against the path of r, if the Location is a relative
+// path. Otherwise err is returned unchanged.
+func resolveRedirect(r *http.Request, err error) error {
+	var rd *Redirect
+	if !errors.As(err, &rd) {
+		return err
+	}
+	if loc := resolveLocation(r.URL.Path, rd.Location); loc != rd.Location {
+		return &locationError{error: err, location: loc}
+	}
+	return err
+}
+
+// resolveLocation resolves loc against the request path reqPath if it is
+// a relative path, in the same way as http.Redirect.
+func resolveLocation(reqPath, loc string) string {
+	u, err := url.Parse(loc)
+	if err != nil || u.Scheme != "" || u.Host != "" || strings.HasPrefix(loc, "/") {
+		return loc
+	}
+	if reqPath == "" {
+		reqPath = "/"
+	}
+	dir, _ := path.Split(reqPath)
+	loc = dir + loc
+	loc, query, hasQuery := strings.Cut(loc, "?")
+	trailing := strings.HasSuffix(loc, "/")
+	loc = path.Clean(loc)
+	if trailing && !strings.HasSuffix(loc, "/") {
+		loc += "/"
+	}
+	if hasQuery {
+		loc += "?" + query
+	}
+	return loc
+}
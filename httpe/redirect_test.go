@@ -0,0 +1,79 @@
+package httpe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirect(t *testing.T) {
+	err := &Redirect{Location: "/login"}
+	require.Equal(t, "Found: /login", err.Error())
+	require.True(t, errors.Is(err, StatusError(http.StatusFound)))
+	require.Equal(t, http.StatusFound, StatusCode(err))
+
+	err = &Redirect{Code: http.StatusMovedPermanently, Location: "https://example.com/"}
+	require.Equal(t, "Moved Permanently: https://example.com/", err.Error())
+	require.Equal(t, http.StatusMovedPermanently, StatusCode(fmt.Errorf("canonical: %w", err)))
+
+	for _, code := range []int{http.StatusOK, http.StatusNotFound, 299, 400} {
+		err = &Redirect{Code: code, Location: "/x"}
+		require.Equal(t, http.StatusFound, StatusCode(err), code)
+		w := httptest.NewRecorder()
+		Must(errHandler(err)).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		require.Equal(t, http.StatusFound, w.Code, code)
+		require.Empty(t, w.Body.String(), code)
+	}
+}
+
+func TestRedirectErrWriters(t *testing.T) {
+	ews := map[string]interface{}{
+		"safe":       WriteSafeErr,
+		"problem":    WriteProblem,
+		"json":       WriteJSONErr,
+		"html":       WriteHTMLErr,
+		"template":   &HTMLErrWriter{},
+		"negotiated": WriteNegotiatedErr,
+	}
+	for name, ew := range ews {
+		h := Must(errHandler(&Redirect{Code: http.StatusSeeOther, Location: "/login?next=%2F"}), ew)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/admin/", nil))
+		require.Equal(t, http.StatusSeeOther, w.Code, name)
+		require.Equal(t, "/login?next=%2F", w.Header().Get("Location"), name)
+		require.Empty(t, w.Body.String(), name)
+		require.Empty(t, w.Header().Get("Content-Type"), name)
+	}
+}
+
+func TestRedirectResolve(t *testing.T) {
+	tests := []struct {
+		path, location, want string
+	}{
+		{"/admin/users/42", "edit", "/admin/users/edit"},
+		{"/admin/users/", "../login?next=users", "/admin/login?next=users"},
+		{"/admin/users/", "./", "/admin/users/"},
+		{"", "login", "/login"},
+		{"/admin/users", "/login", "/login"},
+		{"/admin/users", "https://example.com/login", "https://example.com/login"},
+		{"/admin/users", "//example.com/login", "//example.com/login"},
+		{"/admin/users", ":bad", ":bad"},
+	}
+	for _, tc := range tests {
+		var gotErr error
+		obs := func(_ *http.Request, err error, _ int) { gotErr = err }
+		rd := &Redirect{Location: tc.location}
+		h := Must(errHandler(rd), obs)
+		r := httptest.NewRequest("GET", "/", nil)
+		r.URL.Path = tc.path
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusFound, w.Code, tc)
+		require.Equal(t, tc.want, w.Header().Get("Location"), tc)
+		require.Equal(t, rd, gotErr, tc)
+	}
+}
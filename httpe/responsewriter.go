@@ -0,0 +1,173 @@
+package httpe
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// ResponseState is implemented by the http.ResponseWriter that NewHandler
+// passes to its HandlerE and ErrWriter. It reports what has been written
+// to the response so that an ErrWriter does not write an error response
+// over a response that has already been committed.
+type ResponseState interface {
+	// Status returns the status code written, or 0 if the header has not
+	// been written yet.
+	Status() int
+	// Written returns the number of body bytes written.
+	Written() int64
+	// Committed returns true if the header has been written or the
+	// connection has been hijacked.
+	Committed() bool
+}
+
+// Committed returns true if w implements ResponseState and the response
+// has been committed. The built-in ErrWriters do not write anything for a
+// committed response.
+func Committed(w http.ResponseWriter) bool {
+	rs, ok := w.(ResponseState)
+	return ok && rs.Committed()
+}
+
+// responseWriter wraps an http.ResponseWriter to implement ResponseState.
+type responseWriter struct {
+	http.ResponseWriter
+	status   int
+	written  int64
+	hijacked bool
+}
+
+// WriteHeader records the status code of the response and writes it.
+// Informational 1xx status codes, other than 101 Switching Protocols, do
+// not commit the response.
+func (rw *responseWriter) WriteHeader(code int) {
+	if rw.status == 0 && (code >= 200 || code == http.StatusSwitchingProtocols) {
+		rw.status = code
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes b to the response body, committing the response with 200
+// OK if the header has not been written yet.
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := rw.ResponseWriter.Write(b)
+	rw.written += int64(n)
+	return n, err
+}
+
+// Status returns the status code written, or 0 if none has been.
+func (rw *responseWriter) Status() int { return rw.status }
+
+// Written returns the number of body bytes written.
+func (rw *responseWriter) Written() int64 { return rw.written }
+
+// Committed returns true if the header has been written or the connection
+// has been hijacked.
+func (rw *responseWriter) Committed() bool { return rw.status != 0 || rw.hijacked }
+
+// Unwrap returns the wrapped http.ResponseWriter for use by
+// http.ResponseController.
+func (rw *responseWriter) Unwrap() http.ResponseWriter { return rw.ResponseWriter }
+
+// originalResponseWriter returns the http.ResponseWriter at the end of the
+// Unwrap chain of w, which is w itself if it does not implement Unwrap.
+func originalResponseWriter(w http.ResponseWriter) http.ResponseWriter {
+	for {
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return w
+		}
+		w = u.Unwrap()
+	}
+}
+
+func (rw *responseWriter) flush() {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	rw.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (rw *responseWriter) hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := rw.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil {
+		rw.hijacked = true
+	}
+	return conn, brw, err
+}
+
+func (rw *responseWriter) push(target string, opts *http.PushOptions) error {
+	return rw.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+type flushFunc func()
+
+func (f flushFunc) Flush() { f() }
+
+type hijackFunc func() (net.Conn, *bufio.ReadWriter, error)
+
+func (f hijackFunc) Hijack() (net.Conn, *bufio.ReadWriter, error) { return f() }
+
+type pushFunc func(string, *http.PushOptions) error
+
+func (f pushFunc) Push(target string, opts *http.PushOptions) error { return f(target, opts) }
+
+// wrapResponseWriter returns w wrapped in a responseWriter that implements
+// the same optional http.Flusher, http.Hijacker and http.Pusher interfaces
+// as w. If w already implements ResponseState, it is returned unchanged.
+func wrapResponseWriter(w http.ResponseWriter) http.ResponseWriter {
+	if _, ok := w.(ResponseState); ok {
+		return w
+	}
+	rw := &responseWriter{ResponseWriter: w}
+	_, isFlusher := w.(http.Flusher)
+	_, isHijacker := w.(http.Hijacker)
+	_, isPusher := w.(http.Pusher)
+	f, h, p := flushFunc(rw.flush), hijackFunc(rw.hijack), pushFunc(rw.push)
+	switch {
+	case isFlusher && isHijacker && isPusher:
+		return struct {
+			*responseWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{rw, f, h, p}
+	case isFlusher && isHijacker:
+		return struct {
+			*responseWriter
+			http.Flusher
+			http.Hijacker
+		}{rw, f, h}
+	case isFlusher && isPusher:
+		return struct {
+			*responseWriter
+			http.Flusher
+			http.Pusher
+		}{rw, f, p}
+	case isHijacker && isPusher:
+		return struct {
+			*responseWriter
+			http.Hijacker
+			http.Pusher
+		}{rw, h, p}
+	case isFlusher:
+		return struct {
+			*responseWriter
+			http.Flusher
+		}{rw, f}
+	case isHijacker:
+		return struct {
+			*responseWriter
+			http.Hijacker
+		}{rw, h}
+	case isPusher:
+		return struct {
+			*responseWriter
+			http.Pusher
+		}{rw, p}
+	}
+	return rw
+}
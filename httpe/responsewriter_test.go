@@ -0,0 +1,177 @@
+package httpe
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"foxygo.at/s/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFlusher struct{ flushed bool }
+
+func (f *fakeFlusher) Flush() { f.flushed = true }
+
+type fakeHijacker struct{ err error }
+
+func (h *fakeHijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) { return nil, nil, h.err }
+
+type fakePusher struct{ pushed string }
+
+func (p *fakePusher) Push(target string, _ *http.PushOptions) error {
+	p.pushed = target
+	return nil
+}
+
+type flushHijackPushWriter struct {
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	http.Pusher
+}
+
+func TestWrapResponseWriter(t *testing.T) {
+	base := mock.ResponseWriter()
+	f, h, p := &fakeFlusher{}, &fakeHijacker{}, &fakePusher{}
+	tests := []struct {
+		w                   http.ResponseWriter
+		flush, hijack, push bool
+	}{
+		{base, false, false, false},
+		{struct {
+			http.ResponseWriter
+			http.Flusher
+		}{base, f}, true, false, false},
+		{struct {
+			http.ResponseWriter
+			http.Hijacker
+		}{base, h}, false, true, false},
+		{struct {
+			http.ResponseWriter
+			http.Pusher
+		}{base, p}, false, false, true},
+		{struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Hijacker
+		}{base, f, h}, true, true, false},
+		{struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Pusher
+		}{base, f, p}, true, false, true},
+		{struct {
+			http.ResponseWriter
+			http.Hijacker
+			http.Pusher
+		}{base, h, p}, false, true, true},
+		{flushHijackPushWriter{base, f, h, p}, true, true, true},
+	}
+	for i, tc := range tests {
+		w := wrapResponseWriter(tc.w)
+		_, ok := w.(ResponseState)
+		require.True(t, ok, i)
+		_, ok = w.(http.Flusher)
+		require.Equal(t, tc.flush, ok, i)
+		_, ok = w.(http.Hijacker)
+		require.Equal(t, tc.hijack, ok, i)
+		_, ok = w.(http.Pusher)
+		require.Equal(t, tc.push, ok, i)
+		unwrap := func(w http.ResponseWriter) http.ResponseWriter {
+			return w.(interface{ Unwrap() http.ResponseWriter }).Unwrap()
+		}
+		require.Equal(t, tc.w, unwrap(w), i)
+		require.Equal(t, tc.w, unwrap(wrapResponseWriter(w)), i)
+	}
+}
+
+func TestResponseState(t *testing.T) {
+	w := wrapResponseWriter(mock.ResponseWriter())
+	rs := w.(ResponseState)
+	require.False(t, Committed(w))
+	w.WriteHeader(http.StatusEarlyHints)
+	require.False(t, Committed(w))
+	require.Equal(t, 0, rs.Status())
+	fmt.Fprint(w, "hello")
+	require.True(t, Committed(w))
+	require.Equal(t, http.StatusOK, rs.Status())
+	require.Equal(t, int64(5), rs.Written())
+	w.WriteHeader(http.StatusTeapot)
+	require.Equal(t, http.StatusOK, rs.Status())
+
+	w = wrapResponseWriter(httptest.NewRecorder())
+	w.WriteHeader(http.StatusCreated)
+	require.True(t, Committed(w))
+	require.Equal(t, http.StatusCreated, w.(ResponseState).Status())
+
+	require.False(t, Committed(httptest.NewRecorder()))
+}
+
+func TestResponseStateFlushHijackPush(t *testing.T) {
+	f, h, p := &fakeFlusher{}, &fakeHijacker{}, &fakePusher{}
+	w := wrapResponseWriter(flushHijackPushWriter{mock.ResponseWriter(), f, h, p})
+	w.(http.Flusher).Flush()
+	require.True(t, f.flushed)
+	require.Equal(t, http.StatusOK, w.(ResponseState).Status())
+	w.(http.Flusher).Flush()
+
+	require.NoError(t, w.(http.Pusher).Push("/style.css", nil))
+	require.Equal(t, "/style.css", p.pushed)
+
+	w = wrapResponseWriter(flushHijackPushWriter{mock.ResponseWriter(), f, h, p})
+	h.err = errHijack
+	_, _, err := w.(http.Hijacker).Hijack()
+	require.Equal(t, errHijack, err)
+	require.False(t, Committed(w))
+	h.err = nil
+	_, _, err = w.(http.Hijacker).Hijack()
+	require.NoError(t, err)
+	require.True(t, Committed(w))
+	require.Equal(t, 0, w.(ResponseState).Status())
+}
+
+var errHijack = errors.New("hijack failed")
+
+func TestNewHandlerCommitted(t *testing.T) {
+	var gotStatus int
+	obs := func(_ *http.Request, _ error, status int) { gotStatus = status }
+	h := Must(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, "partial")
+		return ErrBadRequest
+	}, obs)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusAccepted, w.Code)
+	require.Equal(t, "partial", w.Body.String())
+	require.Equal(t, http.StatusAccepted, gotStatus)
+
+	h = Must(func(http.ResponseWriter, *http.Request) error { return ErrBadRequest },
+		func(http.ResponseWriter, error) {}, obs)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusBadRequest, gotStatus)
+}
+
+func TestErrWritersCommitted(t *testing.T) {
+	ews := []ErrWriterFunc{WriteSafeErr, WriteProblem, WriteJSONErr, WriteHTMLErr}
+	for _, ew := range ews {
+		rec := httptest.NewRecorder()
+		w := wrapResponseWriter(rec)
+		fmt.Fprint(w, "ok")
+		ew(w, ErrBadRequest)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "ok", rec.Body.String())
+	}
+	rec := httptest.NewRecorder()
+	w := wrapResponseWriter(rec)
+	fmt.Fprint(w, "ok")
+	WriteNegotiatedErr(w, httptest.NewRequest("GET", "/", nil), ErrBadRequest)
+	require.Equal(t, "ok", rec.Body.String())
+	require.Empty(t, rec.Header().Get("Vary"))
+}
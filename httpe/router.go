@@ -0,0 +1,278 @@
+package httpe
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Router is an http.Handler that serves the routes registered with Handle
+// with an http.ServeMux and keeps a record of them, so that the routes of
+// a service can be listed with WriteRoutes or described as an OpenAPI
+// document with OpenAPI.
+type Router struct {
+	mux    *http.ServeMux
+	routes []*Route
+}
+
+// NewRouter returns a new Router with no routes.
+func NewRouter() *Router {
+	return &Router{mux: http.NewServeMux()}
+}
+
+// Route is the record of a route registered with a Router.
+type Route struct {
+	// Pattern is the pattern the route was registered with, as described
+	// by http.ServeMux.
+	Pattern string
+	// Path is the path of the pattern, without method and host.
+	Path string
+	// Operations are the operations of the route, one for each method
+	// the route is restricted to by its pattern or a Methods handler. A
+	// route that accepts any method has no operations, unless its types
+	// are known, in which case it has one operation with no Method. A
+	// route whose Methods handler does not map the method of its pattern
+	// has no operations either.
+	Operations []Operation
+	// Guards describe the handlers before the last handler of the route,
+	// such as authentication or content type guards. A handler is
+	// described by its String method if it implements fmt.Stringer, such
+	// as a Guard, and by its type otherwise.
+	Guards []string
+	// Summary is a short description of the route.
+	Summary string
+	// Errors are the status codes of the errors the route can return,
+	// other than those of its Operations.
+	Errors []StatusError
+}
+
+// Operation describes a method of a Route. The request and response types
+// are known for a JSON handler, and nil otherwise.
+type Operation struct {
+	Method   string
+	Request  reflect.Type
+	Response reflect.Type
+	// Status is the status code of a successful response, which is 200
+	// unless set with the Status method of the Route.
+	Status int
+	// Errors are the status codes of the errors returned by the
+	// operation's handler when decoding and validating its request.
+	Errors []StatusError
+}
+
+// typedHandler is implemented by handlers with known request and response
+// types, such as the HandlerE returned by JSON.
+type typedHandler interface {
+	types() (req, resp reflect.Type)
+}
+
+var validatorType = reflect.TypeFor[Validator]()
+
+// Handle registers the handler created by passing args to Must for the
+// pattern as described by http.ServeMux, and returns the record of the
+// route. It panics if the args are invalid or the pattern conflicts with
+// one already registered.
+//
+// The methods and types of the operations of the route are taken from the
+// method of the pattern, such as "GET /users/{id}", or else from a method
+// checker such as Get among args, and from the last
+// handler of args if it is a Methods handler or a JSON handler, or a
+// Methods handler of JSON handlers. A Methods handler only has operations
+// for the methods it maps that the pattern or method checker allows. The
+// other handlers, except method checkers, are recorded as Guards.
+func (rt *Router) Handle(pattern string, args ...interface{}) *Route {
+	rt.mux.Handle(pattern, Must(args...))
+	method, path := splitPattern(pattern)
+	route := &Route{Pattern: pattern, Path: path}
+	var handlers []interface{}
+	for _, arg := range args {
+		if _, ok := handlerArg(arg); ok {
+			handlers = append(handlers, arg)
+		}
+	}
+	var last interface{}
+	if len(handlers) > 0 {
+		for _, h := range handlers[:len(handlers)-1] {
+			if m, ok := h.(methodChecker); ok {
+				if method == "" {
+					method = string(m)
+				}
+				continue
+			}
+			route.Guards = append(route.Guards, describeHandler(h))
+		}
+		last = handlers[len(handlers)-1]
+		route.Operations = operations(method, last)
+	}
+	if _, ok := last.(Methods); !ok && method != "" && len(route.Operations) == 0 {
+		route.Operations = []Operation{{Method: method, Status: http.StatusOK}}
+	}
+	if slices.ContainsFunc(route.Operations, func(op Operation) bool { return op.Method != "" }) {
+		route.Errors = []StatusError{ErrMethodNotAllowed}
+	}
+	rt.routes = append(rt.routes, route)
+	return route
+}
+
+// ServeHTTP dispatches the request to the handler of the route whose
+// pattern matches it and implements http.Handler.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt.mux.ServeHTTP(w, r)
+}
+
+// Routes returns the routes of rt in the order they were registered.
+func (rt *Router) Routes() []*Route {
+	return slices.Clone(rt.routes)
+}
+
+// Describe sets the Summary of the route and returns the route.
+func (route *Route) Describe(summary string) *Route {
+	route.Summary = summary
+	return route
+}
+
+// Declare adds the status codes of errs, as returned by StatusCode, to the
+// Errors of the route and returns the route. It is used to document the
+// errors returned by the handlers of a route.
+func (route *Route) Declare(errs ...error) *Route {
+	for _, err := range errs {
+		route.Errors = append(route.Errors, StatusError(StatusCode(err)))
+	}
+	return route
+}
+
+// Status sets the Status of the operations of the route for the given
+// methods, or of all its operations if no methods are given, to code and
+// returns the route. It is used to document the status code of successful
+// responses other than 200, such as 201 Created.
+func (route *Route) Status(code int, methods ...string) *Route {
+	for i, op := range route.Operations {
+		if len(methods) == 0 || slices.Contains(methods, op.Method) {
+			route.Operations[i].Status = code
+		}
+	}
+	return route
+}
+
+// Methods returns the methods of the operations of the route.
+func (route *Route) Methods() []string {
+	methods := make([]string, len(route.Operations))
+	for i, op := range route.Operations {
+		methods[i] = op.Method
+	}
+	return methods
+}
+
+// allErrors returns the sorted status codes of the errors of the route and
+// the operation op, without duplicates.
+func (route *Route) allErrors(op Operation) []StatusError {
+	errs := append(slices.Clone(route.Errors), op.Errors...)
+	slices.Sort(errs)
+	return slices.Compact(errs)
+}
+
+// WriteRoutes writes a table of the routes of rt to w, one line for each
+// operation of a route, with the method, path, request and response types,
+// guards and errors. The method of a route that accepts any method is
+// written as "*", and that of a route without operations for the method of
+// its pattern as "-".
+func (rt *Router) WriteRoutes(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tREQUEST\tRESPONSE\tGUARDS\tERRORS")
+	for _, route := range rt.routes {
+		ops := route.Operations
+		anyMethod := "*"
+		if len(ops) == 0 {
+			ops = []Operation{{}}
+			if method, _ := splitPattern(route.Pattern); method != "" {
+				anyMethod = "-"
+			}
+		}
+		for _, op := range ops {
+			errs := route.allErrors(op)
+			codes := make([]string, len(errs))
+			for i, e := range errs {
+				codes[i] = strconv.Itoa(e.Code())
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", orDefault(op.Method, anyMethod), route.Path,
+				typeName(op.Request), typeName(op.Response),
+				orDefault(strings.Join(route.Guards, ", "), "-"), orDefault(strings.Join(codes, ", "), "-"))
+		}
+	}
+	return tw.Flush()
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return "-"
+	}
+	return t.String()
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// splitPattern returns the method and path of a ServeMux pattern of the
+// form "[METHOD ][HOST]/[PATH]".
+func splitPattern(pattern string) (method, path string) {
+	if m, rest, ok := strings.Cut(pattern, " "); ok {
+		method, pattern = m, strings.TrimLeft(rest, " \t")
+	}
+	if i := strings.Index(pattern, "/"); i > 0 {
+		pattern = pattern[i:]
+	}
+	return method, pattern
+}
+
+// describeHandler returns the description of a handler arg of Handle.
+func describeHandler(h interface{}) string {
+	if s, ok := h.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", h)
+}
+
+// operations returns the operations of the last handler h of a route with
+// the pattern method, which may be empty.
+func operations(method string, h interface{}) []Operation {
+	m, ok := h.(Methods)
+	if !ok {
+		if _, typed := h.(typedHandler); method == "" && !typed {
+			return nil
+		}
+		return []Operation{operation(method, h)}
+	}
+	ops := make([]Operation, 0, len(m))
+	for mm, mh := range m {
+		if method == "" || method == mm {
+			ops = append(ops, operation(mm, mh))
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Method < ops[j].Method })
+	return ops
+}
+
+// operation returns the operation of the handler h for method.
+func operation(method string, h interface{}) Operation {
+	op := Operation{Method: method, Status: http.StatusOK}
+	th, ok := h.(typedHandler)
+	if !ok {
+		return op
+	}
+	op.Request, op.Response = th.types()
+	op.Errors = []StatusError{ErrBadRequest, ErrUnsupportedMediaType}
+	if op.Request.Implements(validatorType) || reflect.PointerTo(op.Request).Implements(validatorType) {
+		op.Errors = append(op.Errors, ErrUnprocessableEntity)
+	}
+	return op
+}
@@ -0,0 +1,128 @@
+package httpe
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type createItemResp struct {
+	item
+}
+
+type deleteItemResp struct{}
+
+func getItem(context.Context, struct{}) (item, error) { return item{ID: "1", Name: "one"}, nil }
+
+func createItem(_ context.Context, req greetReq) (createItemResp, error) {
+	return createItemResp{item{ID: "2", Name: req.Name}}, nil
+}
+
+func deleteItem(context.Context, struct{}) (*deleteItemResp, error) { return &deleteItemResp{}, nil }
+
+func newTestRouter() *Router {
+	rt := NewRouter()
+	rt.Handle("GET /items/{id}", JSON(getItem)).Describe("Get an item").Declare(ErrNotFound)
+	rt.Handle("/items/", AcceptJSON, Guard{Name: "admin only", Handler: errHandler(nil)}, Methods{
+		http.MethodPost:   JSON(createItem),
+		http.MethodDelete: JSON(deleteItem),
+	}).Status(http.StatusCreated, http.MethodPost).Status(http.StatusNoContent, http.MethodDelete)
+	rt.Handle("example.com/health", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+	rt.Handle("POST /files/{path...}", Post, errHandler(nil), WriteProblem)
+	rt.Handle("PUT /{$}", Methods{http.MethodGet: errHandler(nil)})
+	rt.Handle("/greet", JSON(greet))
+	rt.Handle("GET /empty")
+	rt.Handle("/create", Post, errHandler(nil), JSON(createItem))
+	return rt
+}
+
+func TestRouter(t *testing.T) {
+	rt := newTestRouter()
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest("GET", "/items/1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"id": "1", "name": "one"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/health", nil))
+	require.Equal(t, "ok", w.Body.String())
+
+	routes := rt.Routes()
+	require.Len(t, routes, 8)
+	require.Equal(t, "/items/{id}", routes[0].Path)
+	require.Equal(t, "Get an item", routes[0].Summary)
+	require.Equal(t, []StatusError{ErrMethodNotAllowed, ErrNotFound}, routes[0].Errors)
+	want := []Operation{{
+		Method:   "GET",
+		Request:  reflect.TypeFor[struct{}](),
+		Response: reflect.TypeFor[item](),
+		Status:   http.StatusOK,
+		Errors:   []StatusError{ErrBadRequest, ErrUnsupportedMediaType},
+	}}
+	require.Equal(t, want, routes[0].Operations)
+
+	require.Equal(t, []string{"DELETE", "POST"}, routes[1].Methods())
+	require.Equal(t, []string{"Accept(application/json)", "admin only"}, routes[1].Guards)
+	require.Equal(t, http.StatusNoContent, routes[1].Operations[0].Status)
+	require.Equal(t, http.StatusCreated, routes[1].Operations[1].Status)
+	require.Equal(t, []StatusError{ErrBadRequest, ErrUnsupportedMediaType, ErrUnprocessableEntity}, routes[1].Operations[1].Errors)
+
+	require.Equal(t, "/health", routes[2].Path)
+	require.Empty(t, routes[2].Operations)
+	require.Empty(t, routes[2].Errors)
+	require.Equal(t, []string{"POST"}, routes[3].Methods())
+	require.Empty(t, routes[3].Guards)
+	require.Empty(t, routes[4].Operations)
+	require.Equal(t, []string{""}, routes[5].Methods())
+	require.Empty(t, routes[5].Errors)
+	require.Equal(t, []string{"GET"}, routes[6].Methods())
+	require.Equal(t, []string{"POST"}, routes[7].Methods())
+	require.Equal(t, []string{"httpe.HandlerFuncE"}, routes[7].Guards)
+	require.Equal(t, reflect.TypeFor[greetReq](), routes[7].Operations[0].Request)
+
+	require.Panics(t, func() { rt.Handle("/bad", 42) })
+}
+
+func TestWriteRoutes(t *testing.T) {
+	b := &strings.Builder{}
+	require.NoError(t, newTestRouter().WriteRoutes(b))
+	want := `
+METHOD  PATH              REQUEST         RESPONSE               GUARDS                                ERRORS
+GET     /items/{id}       struct {}       httpe.item             -                                     400, 404, 405, 415
+DELETE  /items/           struct {}       *httpe.deleteItemResp  Accept(application/json), admin only  400, 405, 415
+POST    /items/           httpe.greetReq  httpe.createItemResp   Accept(application/json), admin only  400, 405, 415, 422
+*       /health           -               -                      -                                     -
+POST    /files/{path...}  -               -                      -                                     405
+-       /{$}              -               -                      -                                     -
+*       /greet            httpe.greetReq  httpe.greetResp        -                                     400, 415, 422
+GET     /empty            -               -                      -                                     405
+POST    /create           httpe.greetReq  httpe.createItemResp   httpe.HandlerFuncE                    400, 405, 415, 422
+`
+	require.Equal(t, strings.TrimPrefix(want, "\n"), b.String())
+}
+
+func TestRouteStatus(t *testing.T) {
+	rt := NewRouter()
+	route := rt.Handle("/greet", Methods{http.MethodPost: JSON(greet), http.MethodPut: JSON(greet)})
+	require.Equal(t, http.StatusOK, route.Operations[0].Status)
+	require.Equal(t, http.StatusOK, route.Operations[1].Status)
+	route.Status(http.StatusAccepted)
+	require.Equal(t, http.StatusAccepted, route.Operations[0].Status)
+	require.Equal(t, http.StatusAccepted, route.Operations[1].Status)
+	route.Status(http.StatusCreated, http.MethodPut)
+	require.Equal(t, http.StatusAccepted, route.Operations[0].Status)
+	require.Equal(t, http.StatusCreated, route.Operations[1].Status)
+}
@@ -0,0 +1,172 @@
+package httpe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a Server-Sent Event as described in the HTML standard. Data
+// may span multiple lines, separated by CRLF, CR or LF. ID and Event must
+// not contain line breaks.
+// Retry, if not zero, sets the reconnection time of the client.
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+// EventStream sends Server-Sent Events to the client of an SSE handler.
+// It is safe for concurrent use, but must not be used after the function
+// passed to SSE has returned.
+type EventStream struct {
+	w           http.ResponseWriter
+	rc          *http.ResponseController
+	r           *http.Request
+	mu          sync.Mutex
+	started     bool
+	lastEventID string
+}
+
+// LastEventID returns the Last-Event-ID header of the request, the ID of
+// the last event received by a reconnecting client, or "" if none.
+func (s *EventStream) LastEventID() string { return s.lastEventID }
+
+// Start writes the header of the event stream response if it has not
+// been written yet. It is called by Send and only needs to be called
+// directly to commit the response before the first event is sent.
+func (s *EventStream) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.start()
+}
+
+func (s *EventStream) start() error {
+	if s.started {
+		return nil
+	}
+	h := s.w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	s.w.WriteHeader(http.StatusOK)
+	s.started = true
+	return s.rc.Flush()
+}
+
+// Send writes the event e to the stream and flushes it to the client. It
+// returns the error of the request context once the client has
+// disconnected.
+func (s *EventStream) Send(e Event) error {
+	if strings.ContainsAny(e.ID, "\r\n") || strings.ContainsAny(e.Event, "\r\n") {
+		return errors.New("httpe: invalid event: line break in ID or Event")
+	}
+	if err := s.r.Context().Err(); err != nil {
+		return err
+	}
+	b := &strings.Builder{}
+	if e.ID != "" {
+		fmt.Fprintf(b, "id: %s\n", e.ID)
+	}
+	if e.Event != "" {
+		fmt.Fprintf(b, "event: %s\n", e.Event)
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(b, "retry: %d\n", e.Retry.Milliseconds())
+	}
+	for _, line := range strings.Split(lineBreaks.Replace(e.Data), "\n") {
+		fmt.Fprintf(b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	return s.write(b.String())
+}
+
+// lineBreaks replaces the line breaks of the event stream format, CRLF, CR
+// and LF, with LF.
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+func (s *EventStream) write(msg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.start(); err != nil {
+		return err
+	}
+	if _, err := s.w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// heartbeat writes a comment to the stream every d once the stream has
+// started, until done is closed.
+func (s *EventStream) heartbeat(d time.Duration, done <-chan struct{}) {
+	t := time.NewTicker(d)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			s.mu.Lock()
+			if s.started {
+				s.w.Write([]byte(": heartbeat\n\n")) //nolint:errcheck // Failures end the request context.
+				s.rc.Flush()                         //nolint:errcheck // Failures end the request context.
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// SSE returns a HandlerE that streams Server-Sent Events to the client.
+// It calls f with the request and an EventStream to send the events with.
+// f should return when the request context is done, which happens when
+// the client disconnects. Use with Chain or New/Must.
+//
+// The response header is written with the first event, so an error
+// returned by f before then is returned unchanged to be written by the
+// ErrWriter. An error returned by f after the stream has started is sent
+// to the client as an event of type "error" and then returned for the
+// observers. The data of the event is only the text of the StatusError
+// wrapped by the error, or of ErrInternalServerError if there is none, so
+// that no details are leaked. The error is not classified; that is left to
+// the Classifier of the handler. An error returned by f after the client has
+// disconnected is ignored.
+//
+// If heartbeat is greater than zero, a comment is written to the started
+// stream at that interval to keep idle connections open.
+func SSE(f func(r *http.Request, s *EventStream) error, heartbeat time.Duration) HandlerFuncE {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		s := &EventStream{
+			w:           w,
+			rc:          http.NewResponseController(w),
+			r:           r,
+			lastEventID: r.Header.Get("Last-Event-ID"),
+		}
+		var wg sync.WaitGroup
+		done := make(chan struct{})
+		if heartbeat > 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.heartbeat(heartbeat, done)
+			}()
+		}
+		err := f(r, s)
+		close(done)
+		wg.Wait()
+		if err == nil || r.Context().Err() != nil {
+			return nil
+		}
+		if !s.started {
+			return err
+		}
+		sErr := ErrInternalServerError
+		errors.As(err, &sErr)
+		s.Send(Event{Event: "error", Data: sErr.Error()}) //nolint:errcheck // err is returned instead.
+		return err
+	}
+}
@@ -0,0 +1,147 @@
+package httpe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"foxygo.at/s/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSE(t *testing.T) {
+	h := Must(SSE(func(r *http.Request, s *EventStream) error {
+		require.Equal(t, "41", s.LastEventID())
+		if err := s.Send(Event{ID: "42", Event: "progress", Data: "line 1\r\nline 2", Retry: 2 * time.Second}); err != nil {
+			return err
+		}
+		return s.Send(Event{Data: "done"})
+	}, 0))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Last-Event-ID", "41")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.True(t, w.Flushed)
+	require.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	require.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	want := "id: 42\nevent: progress\nretry: 2000\ndata: line 1\ndata: line 2\n\ndata: done\n\n"
+	require.Equal(t, want, w.Body.String())
+}
+
+func TestSSEDataLineBreaks(t *testing.T) {
+	h := Must(SSE(func(_ *http.Request, s *EventStream) error {
+		return s.Send(Event{Data: "hello\revent: admin\rid: 999\n\r\nend"})
+	}, 0))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	want := "data: hello\ndata: event: admin\ndata: id: 999\ndata: \ndata: end\n\n"
+	require.Equal(t, want, w.Body.String())
+}
+
+func TestSSEErr(t *testing.T) {
+	var gotErr error
+	obs := func(_ *http.Request, err error, _ int) { gotErr = err }
+	errSecret := errors.New("secret")
+	tests := map[string]struct {
+		start    bool
+		err      error
+		wantCode int
+		wantBody string
+	}{
+		"pre-stream client": {false, fmt.Errorf("%w: no such job", ErrNotFound), 404, "Not Found: no such job\n"},
+		"pre-stream server": {false, errSecret, 500, "Internal Server Error\n"},
+		"client":            {true, fmt.Errorf("%w: job failed", ErrConflict), 200, "event: error\ndata: Conflict\n\n"},
+		"server":            {true, errSecret, 200, "event: error\ndata: Internal Server Error\n\n"},
+		"server status":     {true, fmt.Errorf("%w: upstream down", ErrBadGateway), 200, "event: error\ndata: Bad Gateway\n\n"},
+		"unclassified":      {true, context.DeadlineExceeded, 200, "event: error\ndata: Internal Server Error\n\n"},
+	}
+	for name, tc := range tests {
+		gotErr = nil
+		h := Must(SSE(func(_ *http.Request, s *EventStream) error {
+			if tc.start {
+				require.NoError(t, s.Start())
+				require.NoError(t, s.Start())
+			}
+			return tc.err
+		}, 0), obs)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		require.Equal(t, tc.wantCode, w.Code, name)
+		require.Equal(t, tc.wantBody, w.Body.String(), name)
+		require.Equal(t, tc.err, gotErr, name)
+	}
+}
+
+func TestSSEDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	h := SSE(func(_ *http.Request, s *EventStream) error {
+		require.NoError(t, s.Send(Event{Data: "1"}))
+		cancel()
+		return s.Send(Event{Data: "2"})
+	}, 0)
+	w := httptest.NewRecorder()
+	require.NoError(t, h.ServeHTTPe(w, r))
+	require.Equal(t, "data: 1\n\n", w.Body.String())
+}
+
+func TestSSESendErr(t *testing.T) {
+	var sendErr error
+	h := SSE(func(_ *http.Request, s *EventStream) error {
+		sendErr = s.Send(Event{ID: "1\n2"})
+		return nil
+	}, 0)
+	require.NoError(t, h.ServeHTTPe(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)))
+	require.EqualError(t, sendErr, "httpe: invalid event: line break in ID or Event")
+
+	errWrite := errors.New("write failed")
+	writers := map[http.ResponseWriter]error{
+		mock.ResponseWriter(): http.ErrNotSupported,
+		struct {
+			http.ResponseWriter
+			http.Flusher
+		}{mock.ResponseWriter().Err(errWrite), &fakeFlusher{}}: errWrite,
+	}
+	for w, want := range writers {
+		err := h.ServeHTTPe(w, httptest.NewRequest("GET", "/", nil))
+		require.NoError(t, err)
+		err = SSE(func(_ *http.Request, s *EventStream) error {
+			return s.Send(Event{Data: "x"})
+		}, 0).ServeHTTPe(w, httptest.NewRequest("GET", "/", nil))
+		require.True(t, errors.Is(err, want), err)
+	}
+}
+
+type heartbeatWriter struct {
+	*httptest.ResponseRecorder
+	once      sync.Once
+	heartbeat chan struct{}
+}
+
+func (w *heartbeatWriter) Write(b []byte) (int, error) {
+	if bytes.HasPrefix(b, []byte(": heartbeat")) {
+		w.once.Do(func() { close(w.heartbeat) })
+	}
+	return w.ResponseRecorder.Write(b)
+}
+
+func TestSSEHeartbeat(t *testing.T) {
+	w := &heartbeatWriter{ResponseRecorder: httptest.NewRecorder(), heartbeat: make(chan struct{})}
+	h := SSE(func(_ *http.Request, s *EventStream) error {
+		if err := s.Send(Event{Data: "start"}); err != nil {
+			return err
+		}
+		<-w.heartbeat
+		return s.Send(Event{Data: "end"})
+	}, time.Millisecond)
+	require.NoError(t, h.ServeHTTPe(w, httptest.NewRequest("GET", "/", nil)))
+	require.Contains(t, w.Body.String(), "data: start\n\n: heartbeat\n\n")
+	require.Contains(t, w.Body.String(), "data: end\n\n")
+}
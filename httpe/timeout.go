@@ -0,0 +1,125 @@
+package httpe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Timeout returns a HandlerE that calls h with a request context that is
+// done after dt, returning an error wrapping err if h has not returned by
+// then. If err is nil, ErrServiceUnavailable is used. Use
+// ErrGatewayTimeout for a handler that times out waiting for another
+// service. If the request is canceled by the client first, the error of
+// the request context is returned.
+//
+// The response written by h is buffered and only written if h returns nil
+// or ErrHandled. If h returns another error, the headers it has set are
+// kept so that the ErrWriter writes them, but the buffered status and body
+// are discarded. If h times out, everything it has written is discarded and
+// its writes fail with http.ErrHandlerTimeout from then on.
+//
+// A panic in h is propagated to the goroutine calling Timeout as a
+// *PanicError that Recover returns unchanged.
+func Timeout(h HandlerE, dt time.Duration, err error) HandlerE {
+	if err == nil {
+		err = ErrServiceUnavailable
+	}
+	f := func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), dt)
+		defer cancel()
+		tw := &timeoutWriter{header: http.Header{}, expired: ctx.Done()}
+		done := make(chan error, 1)
+		panicked := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				if v := recover(); v != nil {
+					panicked <- panicValue(v)
+				}
+			}()
+			hErr := h.ServeHTTPe(tw, r.WithContext(ctx))
+			if ctx.Err() == nil {
+				done <- hErr
+			}
+		}()
+		select {
+		case v := <-panicked:
+			panic(v)
+		case hErr := <-done:
+			copyHeader(w.Header(), tw.header)
+			if hErr == nil || errors.Is(hErr, ErrHandled) {
+				tw.writeTo(w)
+			}
+			return hErr
+		case <-ctx.Done():
+			if cErr := r.Context().Err(); cErr != nil {
+				return cErr
+			}
+			return fmt.Errorf("%w: handler timed out after %v", err, dt)
+		}
+	}
+	return HandlerFuncE(f)
+}
+
+func copyHeader(dst, src http.Header) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// timeoutWriter buffers the response written by the HandlerE of Timeout.
+// Writes fail once expired, the done channel of the handler's context, is
+// closed.
+type timeoutWriter struct {
+	header  http.Header
+	expired <-chan struct{}
+
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	code int
+}
+
+func (tw *timeoutWriter) Header() http.Header { return tw.header }
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.isTimedOut() {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	return tw.buf.Write(b)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if !tw.isTimedOut() && tw.code == 0 && code >= 200 {
+		tw.code = code
+	}
+}
+
+func (tw *timeoutWriter) isTimedOut() bool {
+	select {
+	case <-tw.expired:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeTo writes the buffered status and body to w.
+func (tw *timeoutWriter) writeTo(w http.ResponseWriter) {
+	if tw.code != 0 {
+		w.WriteHeader(tw.code)
+	}
+	if tw.buf.Len() > 0 {
+		_, _ = w.Write(tw.buf.Bytes())
+	}
+}
@@ -0,0 +1,125 @@
+package httpe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeout(t *testing.T) {
+	fast := HandlerFuncE(func(w http.ResponseWriter, _ *http.Request) error {
+		w.Header().Set("X-Test", "fast")
+		w.WriteHeader(http.StatusCreated)
+		w.WriteHeader(http.StatusTeapot)
+		fmt.Fprint(w, "created")
+		return nil
+	})
+	w := httptest.NewRecorder()
+	require.NoError(t, Timeout(fast, time.Second, nil).ServeHTTPe(w, httptest.NewRequest("GET", "/", nil)))
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, "fast", w.Header().Get("X-Test"))
+	require.Equal(t, "created", w.Body.String())
+
+	silent := HandlerFuncE(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusContinue)
+		return nil
+	})
+	w = httptest.NewRecorder()
+	wrapped := wrapResponseWriter(w)
+	require.NoError(t, Timeout(silent, time.Second, nil).ServeHTTPe(wrapped, httptest.NewRequest("GET", "/", nil)))
+	require.False(t, Committed(wrapped))
+
+	failing := HandlerFuncE(func(w http.ResponseWriter, _ *http.Request) error {
+		w.Header().Set("Retry-After", "10")
+		fmt.Fprint(w, "partial")
+		return ErrConflict
+	})
+	w = httptest.NewRecorder()
+	h := Must(Timeout(failing, time.Second, nil))
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusConflict, w.Code)
+	require.Equal(t, "10", w.Header().Get("Retry-After"))
+	require.Equal(t, "Conflict\n", w.Body.String())
+}
+
+func TestTimeoutHandled(t *testing.T) {
+	body := HandlerFuncE(func(w http.ResponseWriter, _ *http.Request) error {
+		fmt.Fprint(w, "body")
+		return nil
+	})
+	h := Must(Timeout(Chain(Conditional(validators(`"v1"`, time.Time{})), body), time.Second, nil))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-None-Match", `"v1"`)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusNotModified, w.Code)
+	require.Equal(t, `"v1"`, w.Header().Get("ETag"))
+	require.Empty(t, w.Body.String())
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "body", w.Body.String())
+}
+
+func TestTimeoutExpired(t *testing.T) {
+	written := make(chan error)
+	slow := HandlerFuncE(func(w http.ResponseWriter, r *http.Request) error {
+		fmt.Fprint(w, "early")
+		<-r.Context().Done()
+		_, err := fmt.Fprint(w, "late")
+		w.WriteHeader(http.StatusTeapot)
+		written <- err
+		return r.Context().Err()
+	})
+	var gotErr error
+	obs := func(_ *http.Request, err error, _ int) { gotErr = err }
+	w := httptest.NewRecorder()
+	Must(Timeout(slow, time.Millisecond, nil), obs).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.ErrHandlerTimeout, <-written)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, "Service Unavailable\n", w.Body.String())
+	require.Equal(t, "Service Unavailable: handler timed out after 1ms", gotErr.Error())
+
+	w = httptest.NewRecorder()
+	Must(Timeout(slow, time.Millisecond, ErrGatewayTimeout)).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.ErrHandlerTimeout, <-written)
+	require.Equal(t, http.StatusGatewayTimeout, w.Code)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	err := Timeout(slow, time.Second, nil).ServeHTTPe(httptest.NewRecorder(), r)
+	require.Equal(t, context.Canceled, err)
+	<-written
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	panicky := HandlerFuncE(func(http.ResponseWriter, *http.Request) error {
+		panic("💥")
+	})
+	err := Recover(Timeout(panicky, time.Second, nil)).ServeHTTPe(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	var pErr *PanicError
+	require.True(t, errors.As(err, &pErr))
+	require.Equal(t, "💥", pErr.Value)
+	require.Contains(t, string(pErr.Stack), "TestTimeoutPanic")
+
+	nested := Timeout(Recover(Timeout(panicky, time.Second, nil)), time.Second, nil)
+	err = nested.ServeHTTPe(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	require.True(t, errors.As(err, &pErr))
+
+	abort := HandlerFuncE(func(http.ResponseWriter, *http.Request) error {
+		panic(http.ErrAbortHandler)
+	})
+	f := func() {
+		_ = Recover(Timeout(abort, time.Second, nil)).ServeHTTPe(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+	require.PanicsWithValue(t, http.ErrAbortHandler, f)
+}
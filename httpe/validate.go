@@ -0,0 +1,97 @@
+package httpe
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidationError is an error describing the fields of a request that
+// failed validation. It wraps ErrUnprocessableEntity so that it is written
+// as a client error, and the fields are added to the problem details
+// document written by WriteProblem and WriteJSONErr as the "errors"
+// extension member.
+//
+// A ValidationError is typically accumulated while validating a decoded
+// request and returned with Err:
+//
+//	func (r *Request) Validate() error {
+//		v := &httpe.ValidationError{}
+//		v.Check(r.Name != "", "name", "required", "name is required")
+//		v.Check(r.Age >= 0, "age", "min", "age must not be negative")
+//		return v.Err()
+//	}
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error returns the error message and implements the error interface.
+func (err *ValidationError) Error() string {
+	return fieldErrorsString(ErrUnprocessableEntity, err.Fields)
+}
+
+// Unwrap returns ErrUnprocessableEntity.
+func (err *ValidationError) Unwrap() error { return ErrUnprocessableEntity }
+
+// ExtendProblem adds the field errors to p as the "errors" extension member
+// and implements ProblemExtender.
+func (err *ValidationError) ExtendProblem(p *Problem) {
+	extendProblemFields(p, err.Fields)
+}
+
+// Add adds a field error for the field at path field.
+func (err *ValidationError) Add(field, code, message string) {
+	err.Fields = append(err.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Addf adds a field error for the field at path field with a message
+// formatted by fmt.Sprintf.
+func (err *ValidationError) Addf(field, code, format string, args ...interface{}) {
+	err.Add(field, code, fmt.Sprintf(format, args...))
+}
+
+// Check adds a field error if ok is false and returns ok.
+func (err *ValidationError) Check(ok bool, field, code, message string) bool {
+	if !ok {
+		err.Add(field, code, message)
+	}
+	return ok
+}
+
+// Merge adds the field errors of verr, if it is or wraps a ValidationError
+// or BindError, with their field paths prefixed by prefix and a dot. It is
+// used to validate nested values, for example
+//
+//	v.Merge("address", r.Address.Validate())
+//
+// Any other non-nil error is added as a field error for prefix with the
+// code "invalid".
+func (err *ValidationError) Merge(prefix string, verr error) {
+	var fields []FieldError
+	var vErr *ValidationError
+	var bErr *BindError
+	switch {
+	case verr == nil:
+		return
+	case errors.As(verr, &vErr):
+		fields = vErr.Fields
+	case errors.As(verr, &bErr):
+		fields = bErr.Fields
+	default:
+		err.Add(prefix, "invalid", verr.Error())
+		return
+	}
+	for _, fe := range fields {
+		if prefix != "" {
+			fe.Field = prefix + "." + fe.Field
+		}
+		err.Fields = append(err.Fields, fe)
+	}
+}
+
+// Err returns err if it has any field errors, otherwise nil.
+func (err *ValidationError) Err() error {
+	if len(err.Fields) == 0 {
+		return nil
+	}
+	return err
+}
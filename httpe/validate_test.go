@@ -0,0 +1,98 @@
+package httpe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type address struct {
+	Street string `json:"street"`
+}
+
+func (a address) Validate() error {
+	v := &ValidationError{}
+	v.Check(a.Street != "", "street", "required", "street is required")
+	return v.Err()
+}
+
+type signup struct {
+	Name    string    `json:"name"`
+	Age     int       `json:"age"`
+	Address address   `json:"address"`
+	Friends []address `json:"friends"`
+}
+
+func (s *signup) Validate() error {
+	v := &ValidationError{}
+	v.Check(s.Name != "", "name", "required", "name is required")
+	if v.Check(s.Age >= 0, "age", "min", "age must not be negative") {
+		v.Check(s.Age < 150, "age", "max", "age must be less than 150")
+	}
+	v.Merge("address", s.Address.Validate())
+	for i, f := range s.Friends {
+		v.Merge(fmt.Sprintf("friends[%d]", i), f.Validate())
+	}
+	return v.Err()
+}
+
+func TestValidationError(t *testing.T) {
+	s := &signup{Name: "kim", Age: 42, Address: address{Street: "Main St"}}
+	require.NoError(t, s.Validate())
+
+	s = &signup{Age: -1, Friends: []address{{Street: "x"}, {}}}
+	err := s.Validate()
+	require.True(t, errors.Is(err, ErrUnprocessableEntity))
+	var vErr *ValidationError
+	require.True(t, errors.As(err, &vErr))
+	want := []FieldError{
+		{Field: "name", Code: "required", Message: "name is required"},
+		{Field: "age", Code: "min", Message: "age must not be negative"},
+		{Field: "address.street", Code: "required", Message: "street is required"},
+		{Field: "friends[1].street", Code: "required", Message: "street is required"},
+	}
+	require.Equal(t, want, vErr.Fields)
+	require.True(t, strings.HasPrefix(err.Error(), "Unprocessable Entity: name: name is required; age: "))
+}
+
+func TestValidationErrorMerge(t *testing.T) {
+	v := &ValidationError{}
+	v.Merge("x", nil)
+	require.NoError(t, v.Err())
+
+	v.Merge("", &BindError{Fields: []FieldError{{Field: "id", In: "path", Code: "required", Message: "required"}}})
+	v.Merge("when", errors.New("not a date"))
+	v.Addf("count", "max", "count must be at most %d", 10)
+	want := []FieldError{
+		{Field: "id", In: "path", Code: "required", Message: "required"},
+		{Field: "when", Code: "invalid", Message: "not a date"},
+		{Field: "count", Code: "max", Message: "count must be at most 10"},
+	}
+	require.Equal(t, want, v.Fields)
+}
+
+func TestValidationErrorProblem(t *testing.T) {
+	h := NewHandler(JSON(func(context.Context, signup) (struct{}, error) {
+		return struct{}{}, nil
+	}), WithErrWriterFunc(WriteProblem))
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": "kim", "age": 200}`))
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	want := `{
+		"title": "Unprocessable Entity",
+		"status": 422,
+		"detail": "Unprocessable Entity: age: age must be less than 150; address.street: street is required",
+		"errors": [
+			{"field": "age", "code": "max", "message": "age must be less than 150"},
+			{"field": "address.street", "code": "required", "message": "street is required"}
+		]
+	}`
+	require.JSONEq(t, want, w.Body.String())
+}